@@ -0,0 +1,251 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var Aggregations = map[string]Aggregation {
+	"sum": {Name: "sum", Function: Sum, Derivative: SumDerivative},
+	"mean": {Name: "mean", Function: Mean, Derivative: MeanDerivative},
+	"max": {Name: "max", Function: Maximum, Derivative: MaximumDerivative},
+	"min": {Name: "min", Function: Minimum, Derivative: MinimumDerivative},
+	"product": {Name: "product", Function: Product, Derivative: ProductDerivative},
+}
+
+type Aggregation struct {
+	Name string
+	Function func([]float64)float64
+	Derivative func([]float64, int)float64
+}
+
+func Sum (inputs []float64) float64 {
+	var sum float64
+	for _, input := range inputs {
+		sum = sum + input
+	}
+	return sum
+}
+
+func SumDerivative (inputs []float64, i int) float64 {
+	return 1
+}
+
+func Mean (inputs []float64) float64 {
+	if len(inputs) == 0 {return 0}
+	return Sum(inputs) / float64(len(inputs))
+}
+
+func MeanDerivative (inputs []float64, i int) float64 {
+	return 1 / float64(len(inputs))
+}
+
+func extreme (inputs []float64, larger bool) int {
+	best := 0
+	for i, input := range inputs {
+		if (larger && input > inputs[best]) || (!larger && input < inputs[best]) {best = i}
+	}
+	return best
+}
+
+func Maximum (inputs []float64) float64 {
+	if len(inputs) == 0 {return 0}
+	return inputs[extreme(inputs, true)]
+}
+
+func MaximumDerivative (inputs []float64, i int) float64 {
+	if extreme(inputs, true) == i {return 1}
+	return 0
+}
+
+func Minimum (inputs []float64) float64 {
+	if len(inputs) == 0 {return 0}
+	return inputs[extreme(inputs, false)]
+}
+
+func MinimumDerivative (inputs []float64, i int) float64 {
+	if extreme(inputs, false) == i {return 1}
+	return 0
+}
+
+func Product (inputs []float64) float64 {
+	product := 1.0
+	for _, input := range inputs {
+		product = product * input
+	}
+	return product
+}
+
+func ProductDerivative (inputs []float64, i int) float64 {
+	product := 1.0
+	for k, input := range inputs {
+		if k != i {product = product * input}
+	}
+	return product
+}
+
+func aggregator (layer Layer) Aggregation {
+	if layer.Aggregation.Function == nil {return Aggregations["sum"]}
+	return layer.Aggregation
+}
+
+func (aggregation Aggregation) MarshalJSON () ([]byte, error) {
+	if aggregation.Name == "" {return json.Marshal("sum")}
+	if _, ok := Aggregations[aggregation.Name]; !ok {return nil, fmt.Errorf("ann: unregistered aggregation %q", aggregation.Name)}
+	return json.Marshal(aggregation.Name)
+}
+
+func (aggregation *Aggregation) UnmarshalJSON (data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {return err}
+	registered, ok := Aggregations[name]
+	if !ok {return fmt.Errorf("ann: unregistered aggregation %q", name)}
+	*aggregation = registered
+	return nil
+}
+
+func AggregateDendrite (signals int, aggregation Aggregation, inputchan chan Signal, outputchan chan Signal, partialchan chan []Signal, faultchan chan Fault, cancelchan chan struct{}) {
+	live := make([]bool, signals)
+	for i := range live {
+		live[i] = true
+	}
+	LiveDendrite(live, aggregation, inputchan, outputchan, partialchan, nil, faultchan, cancelchan)
+}
+
+func slots (live []bool) []int {
+	var indices []int
+	for i, present := range live {
+		if present {indices = append(indices, i)}
+	}
+	return indices
+}
+
+func LiveDendrite (live []bool, aggregation Aggregation, inputchan chan Signal, outputchan chan Signal, partialchan chan []Signal, livechan chan []bool, faultchan chan Fault, cancelchan chan struct{}) {
+	arrivals := map[moment][]Signal {}
+	signalcounts := map[moment]int {}
+	indices := slots(live)
+	column := make([]float64, len(indices))
+	timer := time.NewTimer(DendriteTimeout); timer.Stop()
+	var stall <- chan time.Time
+	for {
+		resize := livechan
+		if len(arrivals) > 0 {resize = nil}
+		select {
+		case live = <- resize:
+			indices = slots(live)
+			column = make([]float64, len(indices))
+			if devsignal {fmt.Printf("\n%v: Dendrite resized to %d signals...\n", time.Now(), len(indices))}
+		case input := <- inputchan:
+			if input.Source < 0 || input.Source >= len(live) || !live[input.Source] {
+				ReportFault(Fault {Kind: "unknown", Sample: input.Sample, Step: input.Step, Source: input.Source}, faultchan)
+				continue
+			}
+			arrived, ok := arrivals[input.moment()]
+			if !ok {
+				arrived = make([]Signal, len(live))
+				arrivals[input.moment()] = arrived
+			}
+			if arrived[input.Source].Values != nil {
+				ReportFault(Fault {Kind: "duplicate", Sample: input.Sample, Step: input.Step, Source: input.Source}, faultchan)
+				continue
+			}
+			if input.Values == nil {input.Values = []float64 {}}
+			arrived[input.Source] = input
+			signalcounts[input.moment()]++
+			if signalcounts[input.moment()] == len(indices) {
+				delete(arrivals, input.moment()); delete(signalcounts, input.moment())
+				output := Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(arrived[indices[0]].Values))}
+				var partials []Signal
+				if partialchan != nil && !input.Infer {
+					partials = make([]Signal, len(live))
+					for i := range partials {
+						partials[i] = Signal {Sample: input.Sample, Step: input.Step, Source: i, Values: make([]float64, len(output.Values))}
+					}
+				}
+				for b := range output.Values {
+					for k, i := range indices {
+						column[k] = 0
+						if b < len(arrived[i].Values) {column[k] = arrived[i].Values[b]}
+					}
+					output.Values[b] = aggregation.Function(column)
+					if partials == nil {continue}
+					for k, i := range indices {
+						partials[i].Values[b] = aggregation.Derivative(column, k)
+					}
+				}
+				if devsignal {fmt.Printf("\n%v: Aggregate dendrite relayed sample %d %v...\n", time.Now(), output.Sample, output.Values)}
+				if !PushSignalOrCancel(output, outputchan, cancelchan) {return}
+				if partials != nil {
+					select {
+					case partialchan <- partials:
+					case <- cancelchan:
+						return
+					}
+				}
+			}
+			stall = nil
+			if len(arrivals) > 0 && DendriteTimeout > 0 {
+				timer.Stop(); timer.Reset(DendriteTimeout)
+				stall = timer.C
+			}
+		case <- stall:
+			for key, arrived := range arrivals {
+				fault := Fault {Kind: "missing", Sample: key.sample, Step: key.step, Source: -1}
+				for _, source := range indices {
+					if arrived[source].Values == nil {fault.Missing = append(fault.Missing, source)}
+				}
+				ReportFault(fault, faultchan)
+			}
+			stall = nil
+		case <- cancelchan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func Scatter (inputchan chan Signal, partialchan chan []Signal, outputchans []chan Signal, cancelchan chan struct{}) {
+	LiveScatter(inputchan, partialchan, outputchans, nil, cancelchan)
+}
+
+func LiveScatter (inputchan chan Signal, partialchan chan []Signal, outputchans []chan Signal, lanechan chan []chan Signal, cancelchan chan struct{}) {
+	stored := map[moment][]Signal {}
+	for {
+		var input Signal
+		select {
+		case outputchans = <- lanechan:
+			continue
+		case partials := <- partialchan:
+			stored[partials[0].moment()] = partials
+			continue
+		case input = <- inputchan:
+		case <- cancelchan:
+			return
+		}
+		partials, ok := stored[input.moment()]
+		for !ok {
+			select {
+			case arrived := <- partialchan:
+				stored[arrived[0].moment()] = arrived
+				partials, ok = stored[input.moment()]
+			case <- cancelchan:
+				return
+			}
+		}
+		delete(stored, input.moment())
+		for pass := 0; pass < 2; pass++ {
+			if pass == 1 {
+				if ok, input = PullSignalOrCancel(inputchan, cancelchan); !ok {return}
+			}
+			for i, outputchan := range outputchans {
+				if outputchan == nil {continue}
+				output := Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(input.Values))}
+				for b, value := range input.Values {
+					if b < len(partials[i].Values) {output.Values[b] = value * partials[i].Values[b]}
+				}
+				if !PushSignalOrCancel(output, outputchan, cancelchan) {return}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Aggregations_Derivative_Accuracy (t *testing.T) {
+	inputs := []float64 {0.5, -2, 3}
+	for name, aggregation := range Aggregations {
+		for i := range inputs {
+			shifted := append([]float64 {}, inputs...)
+			shifted[i] = shifted[i] + 1e-6
+			numeric := (aggregation.Function(shifted) - aggregation.Function(inputs)) / 1e-6
+			if math.Abs(numeric - aggregation.Derivative(inputs, i)) > 1e-4 {
+				t.Log("Failure - Aggregation derivative inaccurate")
+				t.Log(name, i, numeric, aggregation.Derivative(inputs, i))
+				t.Fail()
+				return
+			}
+		}
+	}
+	t.Log("Success - Aggregation derivatives match finite differences")
+}
+
+func Test_AggregateDendrite_Partials (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 5
+	inputchan := make(chan Signal); outputchan := make(chan Signal); partialchan := make(chan []Signal)
+
+	go AggregateDendrite(3, Aggregations["product"], inputchan, outputchan, partialchan, nil, cancelchan)
+	go func() {
+		inputchan <- Signal {Source: 2, Values: []float64 {4}}; inputchan <- Signal {Source: 0, Values: []float64 {2}}; inputchan <- Signal {Source: 1, Values: []float64 {3}}
+		if result := <- outputchan; result.Values[0] != 24 {
+			t.Log("Failure - Aggregate dendrite product inaccurate")
+			t.Log(result.Values)
+			return
+		}
+		if partials := <- partialchan; partials[0].Values[0] != 12 || partials[1].Values[0] != 8 || partials[2].Values[0] != 6 {
+			t.Log("Failure - Aggregate dendrite partials inaccurate")
+			t.Log(partials)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Aggregate dendrite timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Aggregate dendrite product and partials are accurate")
+		return
+	}
+}
+
+func Test_Network_Aggregation_Gradients (t *testing.T) {
+	resultchan := make(chan []GradientCheck)
+	var timeout time.Duration = 500
+	set := TrainingSet {Input: []float64 {0.3, -0.8}, Expect: []float64 {1}}
+
+	go func() {
+		var checks []GradientCheck
+		for _, name := range []string {"mean", "max", "min", "product"} {
+			layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"], Aggregation: Aggregations[name]}, {Neurons: 1, Activation: Activations["sigmoid"], Aggregation: Aggregations[name]}}
+			network, _ := NewNetwork(0.1, 2, layers, RandomWeights(2, layers, 5))
+			named, _ := CheckGradients(network, set, 1e-6)
+			checks = append(checks, WorstGradient(named))
+			network.Close()
+		}
+		resultchan <- checks
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Aggregation gradient check timed out")
+		t.Fail()
+		return
+	case checks := <- resultchan:
+		for _, check := range checks {
+			if check.RelativeError > 1e-5 && math.Abs(check.Analytic - check.Numeric) > 1e-8 {
+				t.Log("Failure - Aggregation gradients disagree with finite differences")
+				t.Log(check)
+				t.Fail()
+				return
+			}
+		}
+		t.Log("Success - Aggregation gradients agree with finite differences")
+		return
+	}
+}
+
+func Test_Matrix_Aggregation_CrossCheck (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"], Aggregation: Aggregations["product"]}, {Neurons: 2, Activation: Activations["sigmoid"], Aggregation: Aggregations["max"]}}
+	weights := RandomWeights(2, layers, 19)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {0.2, 0.9}, Expect: []float64 {0, 1}},
+		{Input: []float64 {0.8, 0.1}, Expect: []float64 {1, 0}},
+	}}
+
+	go func() {
+		var divergences []Divergence
+		for epoch := 0; epoch < 10; epoch++ {
+			divergences = append(divergences, CrossCheck(network, matrix, regimen)...)
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Aggregation cross check timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Matrix engine aggregation diverged from the network")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		var buffer bytes.Buffer
+		network.Save(&buffer)
+		model, err := LoadModel(&buffer)
+		if err != nil || model.Layers[0].Aggregation.Name != "product" || model.Layers[1].Aggregation.Name != "max" {
+			t.Log("Failure - Aggregation lost in the saved model")
+			t.Log(err)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Matrix engine aggregation matches the network")
+		return
+	}
+}
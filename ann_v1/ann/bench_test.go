@@ -0,0 +1,214 @@
+package ann
+
+import (
+	"fmt"
+	"testing"
+)
+
+func reportSamples (b *testing.B, samples int) {
+	if seconds := b.Elapsed().Seconds(); seconds > 0 {
+		b.ReportMetric(float64(samples) / seconds, "samples/sec")
+	}
+}
+
+func Benchmark_SignalNucleus_Loop (b *testing.B) {
+	internals := NewSignalPeripherals()
+	cancelchan := make(chan struct{})
+	defer close(cancelchan)
+
+	go SignalNucleus (0.1, Activations["sigmoid"], internals, cancelchan)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		internals.Input <- Scalar(0.5); <- internals.Output
+		internals.Upfeed <- Scalar(0.5); <- internals.Downfeed; <- internals.Downfeed
+	}
+	reportSamples(b, b.N)
+}
+
+func Benchmark_SignalAxon_Broadcast (b *testing.B) {
+	for _, signals := range []int {1, 8, 64} {
+		b.Run(fmt.Sprintf("signals=%d", signals), func (b *testing.B) {
+			cancelchan := make(chan struct{}); inputchan := make(chan Signal); outputchan := make(chan Signal)
+			defer close(cancelchan)
+
+			go SignalAxon (signals, inputchan, outputchan, cancelchan)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				inputchan <- Scalar(1)
+				for s := 0; s < signals; s++ {
+					<- outputchan
+				}
+			}
+			reportSamples(b, b.N)
+		})
+	}
+}
+
+func Benchmark_SignalSynapse_Loop (b *testing.B) {
+	for _, size := range []int {1, 16, 64} {
+		b.Run(fmt.Sprintf("batch=%d", size), func (b *testing.B) {
+			internals := NewSignalPeripherals()
+			cancelchan := make(chan struct{})
+			defer close(cancelchan)
+			input := Fill(size, 1); errormargin := Fill(size, 0.1); adjustment := Fill(size, 0)
+
+			go SignalSynapse (0.5, internals, Probe {}, cancelchan)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				internals.Input <- input; <- internals.Output
+				internals.Upfeed <- errormargin; <- internals.Downfeed; internals.Upfeed <- adjustment
+			}
+			reportSamples(b, b.N * size)
+		})
+	}
+}
+
+func Benchmark_SignalDendrite_Summation (b *testing.B) {
+	for _, signals := range []int {1, 8, 64} {
+		b.Run(fmt.Sprintf("signals=%d", signals), func (b *testing.B) {
+			cancelchan := make(chan struct{}); inputchan := make(chan Signal); outputchan := make(chan Signal)
+			defer close(cancelchan)
+			input := Fill(16, 1)
+
+			go SignalDendrite (signals, inputchan, outputchan, cancelchan)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				input.Sample = n
+				for s := 0; s < signals; s++ {
+					inputchan <- input
+				}
+				<- outputchan
+			}
+			reportSamples(b, b.N * 16)
+		})
+	}
+}
+
+func Benchmark_SignalNeuron_Loop (b *testing.B) {
+	cancelchan := make(chan struct{})
+	defer close(cancelchan)
+	neuron := NewSignalPeripherals()
+	input := Fill(16, 1); errormargin := Fill(16, 0.5)
+
+	NewSignalNeuron(0.1, Synapses {Ingoing: 1, Outgoing: 1}, Activations["sigmoid"], neuron, cancelchan)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		neuron.Input <- input; <- neuron.Output
+		neuron.Upfeed <- errormargin; <- neuron.Downfeed; <- neuron.Downfeed
+	}
+	reportSamples(b, b.N * 16)
+}
+
+var benchmarkShapes =[]struct {
+	inputs int
+	layers []int
+} {
+	{inputs: 2, layers: []int {2, 1}},
+	{inputs: 8, layers: []int {16, 4}},
+	{inputs: 32, layers: []int {64, 32, 10}},
+}
+
+func benchmarkNetwork (inputs int, sizes []int) (*Network, []float64, TrainingSet) {
+	layers := make([]Layer, len(sizes))
+	for l, size := range sizes {
+		layers[l] = Layer {Neurons: size, Activation: Activations["sigmoid"]}
+	}
+	input := make([]float64, inputs)
+	for i := range input {
+		input[i] = float64(i % 2)
+	}
+	set := TrainingSet {Input: input, Expect: make([]float64, sizes[len(sizes) - 1])}
+	network, _ := NewNetwork(0.1, inputs, layers, RandomWeights(inputs, layers, 1))
+	return network, input, set
+}
+
+func Benchmark_Network_Forward (b *testing.B) {
+	for _, shape := range benchmarkShapes {
+		b.Run(fmt.Sprintf("shape=%d-%v", shape.inputs, shape.layers), func (b *testing.B) {
+			network, input, _ := benchmarkNetwork(shape.inputs, shape.layers)
+			defer network.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				network.Predict(input)
+			}
+			reportSamples(b, b.N)
+		})
+	}
+}
+
+func Benchmark_Network_ForwardBackward (b *testing.B) {
+	for _, shape := range benchmarkShapes {
+		b.Run(fmt.Sprintf("shape=%d-%v", shape.inputs, shape.layers), func (b *testing.B) {
+			network, _, set := benchmarkNetwork(shape.inputs, shape.layers)
+			defer network.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				network.Learn(set)
+			}
+			reportSamples(b, b.N)
+		})
+	}
+}
+
+func Benchmark_Network_Batch (b *testing.B) {
+	for _, size := range []int {1, 16, 64} {
+		b.Run(fmt.Sprintf("batch=%d", size), func (b *testing.B) {
+			network, _, set := benchmarkNetwork(8, []int {16, 4})
+			defer network.Close()
+			batch := make([]TrainingSet, size)
+			for s := range batch {
+				batch[s] = set
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				network.LearnBatch(batch)
+			}
+			reportSamples(b, b.N * size)
+		})
+	}
+}
+
+func Benchmark_Network_Stream (b *testing.B) {
+	network, input, _ := benchmarkNetwork(8, []int {16, 16, 4})
+	defer network.Close()
+	samples := make(chan Sample)
+	predictions := network.Stream(samples)
+	b.ReportAllocs()
+	b.ResetTimer()
+	go func () {
+		for n := 0; n < b.N; n++ {
+			samples <- Sample {Id: n, Input: input}
+		}
+		close(samples)
+	}()
+	for range predictions {
+	}
+	reportSamples(b, b.N)
+}
+
+func Benchmark_Matrix_ForwardBackward (b *testing.B) {
+	for _, shape := range benchmarkShapes {
+		b.Run(fmt.Sprintf("shape=%d-%v", shape.inputs, shape.layers), func (b *testing.B) {
+			layers := make([]Layer, len(shape.layers))
+			for l, size := range shape.layers {
+				layers[l] = Layer {Neurons: size, Activation: Activations["sigmoid"]}
+			}
+			_, _, set := benchmarkNetwork(shape.inputs, shape.layers)
+			matrix := NewMatrix(0.1, shape.inputs, layers, RandomWeights(shape.inputs, layers, 1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				matrix.Learn(set)
+			}
+			reportSamples(b, b.N)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const devboltzmann bool = false
+
+type RBM struct {
+	LearnRate float64
+	Visible int
+	Hidden int
+	Weights [][][]float64
+	random *rand.Rand
+}
+
+func NewRBM (visible int, hidden int, learnrate float64, seed int64) *RBM {
+	rbm := &RBM {LearnRate: learnrate, Visible: visible, Hidden: hidden, random: rand.New(rand.NewSource(seed))}
+	rbm.Weights = [][][]float64 {make([][]float64, hidden), make([][]float64, visible)}
+	for j := range rbm.Weights[0] {
+		rbm.Weights[0][j] = make([]float64, visible + 1)
+		for i := 0; i < visible; i++ {
+			rbm.Weights[0][j][i] = (rbm.random.Float64() * 2 - 1) * 0.1
+		}
+	}
+	for i := range rbm.Weights[1] {
+		rbm.Weights[1][i] = []float64 {0}
+	}
+	return rbm
+}
+
+func (rbm *RBM) Layers () []Layer {
+	return []Layer {{Neurons: rbm.Hidden, Activation: Activations["sigmoid"]}, {Neurons: rbm.Visible, Activation: Activations["sigmoid"], Tie: &Tie {Layer: 0, Transpose: true}}}
+}
+
+func (rbm *RBM) hidden (visible []float64) []float64 {
+	probabilities := make([]float64, rbm.Hidden)
+	for j, weights := range rbm.Weights[0] {
+		excitement := weights[rbm.Visible]
+		for i, value := range visible {
+			excitement = excitement + weights[i] * value
+		}
+		probabilities[j] = Sigmoid(excitement)
+	}
+	return probabilities
+}
+
+func (rbm *RBM) visible (hidden []float64) []float64 {
+	probabilities := make([]float64, rbm.Visible)
+	for i := range probabilities {
+		excitement := rbm.Weights[1][i][0]
+		for j, value := range hidden {
+			excitement = excitement + rbm.Weights[0][j][i] * value
+		}
+		probabilities[i] = Sigmoid(excitement)
+	}
+	return probabilities
+}
+
+func (rbm *RBM) sample (probabilities []float64) []float64 {
+	states := make([]float64, len(probabilities))
+	for i, probability := range probabilities {
+		if rbm.random.Float64() < probability {states[i] = 1}
+	}
+	return states
+}
+
+func (rbm *RBM) Train (regimen Regimen, epochs int, steps int) {
+	if steps < 1 {steps = 1}
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, set := range regimen.TrainingSets {
+			positive := rbm.hidden(set.Input)
+			reconstruction, negative := set.Input, positive
+			for k := 0; k < steps; k++ {
+				reconstruction = rbm.visible(rbm.sample(negative))
+				negative = rbm.hidden(reconstruction)
+			}
+			for j := range rbm.Weights[0] {
+				for i := 0; i < rbm.Visible; i++ {
+					rbm.Weights[0][j][i] = rbm.Weights[0][j][i] + rbm.LearnRate * (positive[j] * set.Input[i] - negative[j] * reconstruction[i])
+				}
+				rbm.Weights[0][j][rbm.Visible] = rbm.Weights[0][j][rbm.Visible] + rbm.LearnRate * (positive[j] - negative[j])
+			}
+			for i := range rbm.Weights[1] {
+				rbm.Weights[1][i][0] = rbm.Weights[1][i][0] + rbm.LearnRate * (set.Input[i] - reconstruction[i])
+			}
+		}
+		if devboltzmann {fmt.Printf("\n%v: Epoch %d reconstruction error [%f]...\n", time.Now(), epoch, rbm.ReconstructionError(regimen))}
+	}
+}
+
+func (rbm *RBM) Reconstruct (input []float64) []float64 {
+	return rbm.visible(rbm.hidden(input))
+}
+
+func (rbm *RBM) ReconstructionError (regimen Regimen) float64 {
+	var sum float64
+	for _, set := range regimen.TrainingSets {
+		for i, value := range rbm.Reconstruct(set.Input) {
+			sum = sum + (value - set.Input[i]) * (value - set.Input[i])
+		}
+	}
+	return sum / float64(len(regimen.TrainingSets) * rbm.Visible)
+}
+
+func (rbm *RBM) FreeEnergy (input []float64) float64 {
+	var energy float64
+	for i, value := range input {
+		energy = energy - rbm.Weights[1][i][0] * value
+	}
+	for _, weights := range rbm.Weights[0] {
+		excitement := weights[rbm.Visible]
+		for i, value := range input {
+			excitement = excitement + weights[i] * value
+		}
+		energy = energy - math.Log1p(math.Exp(excitement))
+	}
+	return energy
+}
+
+func (rbm *RBM) Model () Model {
+	return Model {LearnRate: rbm.LearnRate, Inputs: rbm.Visible, Layers: rbm.Layers(), Weights: copyWeights(rbm.Weights)}
+}
+
+func (rbm *RBM) Save (writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(rbm.Model())
+}
+
+func LoadRBM (reader io.Reader, seed int64) (*RBM, error) {
+	model, err := LoadModel(reader)
+	if err != nil {return nil, err}
+	if len(model.Layers) != 2 || model.Layers[1].Tie == nil || !model.Layers[1].Tie.Transpose || model.Layers[1].Neurons != model.Inputs {return nil, fmt.Errorf("ann: model is not a restricted Boltzmann machine")}
+	return &RBM {LearnRate: model.LearnRate, Visible: model.Inputs, Hidden: model.Layers[0].Neurons, Weights: model.Weights, random: rand.New(rand.NewSource(seed))}, nil
+}
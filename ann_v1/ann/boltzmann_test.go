@@ -0,0 +1,89 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_RBM_Reconstruction (t *testing.T) {
+	patterns := [][]float64 {{1, 1, 1, 0, 0, 0}, {0, 0, 0, 1, 1, 1}}
+	var regimen Regimen
+	for _, pattern := range patterns {
+		regimen.TrainingSets = append(regimen.TrainingSets, TrainingSet {Input: pattern})
+	}
+	rbm := NewRBM(6, 3, 0.1, 5)
+	before := rbm.ReconstructionError(regimen)
+	rbm.Train(regimen, 1000, 1)
+	after := rbm.ReconstructionError(regimen)
+	if after > 0.1 || after >= before {
+		t.Log("Failure - Contrastive divergence did not learn the patterns")
+		t.Log(before, after)
+		t.Fail()
+		return
+	}
+	stranger := []float64 {1, 0, 1, 0, 1, 0}
+	for _, pattern := range patterns {
+		if rbm.FreeEnergy(pattern) >= rbm.FreeEnergy(stranger) {
+			t.Log("Failure - Training patterns do not have the lowest free energy")
+			t.Log(rbm.FreeEnergy(pattern), rbm.FreeEnergy(stranger))
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Restricted Boltzmann machine reconstructs its training patterns")
+}
+
+func Test_RBM_Network (t *testing.T) {
+	resultchan := make(chan [][]float64)
+	var timeout time.Duration = 2000
+	regimen := Regimen {TrainingSets: []TrainingSet {{Input: []float64 {1, 0, 1, 0}}, {Input: []float64 {0, 1, 0, 1}}}}
+	rbm := NewRBM(4, 2, 0.1, 2)
+	rbm.Train(regimen, 50, 2)
+	var saved bytes.Buffer
+	if err := rbm.Save(&saved); err != nil {
+		t.Log("Failure - Restricted Boltzmann machine could not be saved")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	loaded, err := LoadRBM(bytes.NewReader(saved.Bytes()), 0)
+	if err != nil {
+		t.Log("Failure - Restricted Boltzmann machine could not be loaded")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	network, _ := loaded.Model().Network()
+	defer network.Close()
+
+	go func() {
+		var predicted [][]float64
+		for _, set := range regimen.TrainingSets {
+			predicted = append(predicted, network.Predict(set.Input))
+		}
+		resultchan <- predicted
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Tied network timed out")
+		t.Fail()
+		return
+	case predicted := <- resultchan:
+		for s, set := range regimen.TrainingSets {
+			expected := rbm.Reconstruct(set.Input)
+			for i := range expected {
+				if math.Abs(predicted[s][i] - expected[i]) > 1e-9 {
+					t.Log("Failure - Tied network does not reproduce the mean-field reconstruction")
+					t.Log(predicted[s], expected)
+					t.Fail()
+					return
+				}
+			}
+		}
+		t.Log("Success - Restricted Boltzmann machine runs as a tied network")
+		return
+	}
+}
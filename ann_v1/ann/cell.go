@@ -0,0 +1,300 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const devcell bool = false
+
+var Cells = map[string]Cell {
+	"lstm": {
+		Name: "lstm",
+		Channels: []Channel {{Input: true, Recurrent: true}, {Input: true, Recurrent: true}, {Input: true, Recurrent: true}, {Input: true, Recurrent: true}},
+		Forward: LSTMForward,
+		Backward: LSTMBackward,
+	},
+	"gru": {
+		Name: "gru",
+		Channels: []Channel {{Input: true, Recurrent: true}, {Input: true, Recurrent: true}, {Input: true}, {Recurrent: true}},
+		Forward: GRUForward,
+		Backward: GRUBackward,
+	},
+}
+
+type Channel struct {
+	Input bool
+	Recurrent bool
+}
+
+type Cell struct {
+	Name string
+	Channels []Channel
+	Forward func([]float64, float64)(float64, float64)
+	Backward func([]float64, float64, float64, float64)([]float64, float64)
+}
+
+type trace struct {
+	excitements [][]float64
+	state []float64
+}
+
+func LSTMForward (excitements []float64, state float64) (float64, float64) {
+	input, forget, candidate, output := Sigmoid(excitements[0]), Sigmoid(excitements[1]), math.Tanh(excitements[2]), Sigmoid(excitements[3])
+	cell := forget * state + input * candidate
+	return output * math.Tanh(cell), cell
+}
+
+func LSTMBackward (excitements []float64, state float64, errormargin float64, carry float64) ([]float64, float64) {
+	input, forget, candidate, output := Sigmoid(excitements[0]), Sigmoid(excitements[1]), math.Tanh(excitements[2]), Sigmoid(excitements[3])
+	cell := math.Tanh(forget * state + input * candidate)
+	margin := errormargin * output * (1 - cell * cell) + carry
+	return []float64 {
+		margin * candidate * input * (1 - input),
+		margin * state * forget * (1 - forget),
+		margin * input * (1 - candidate * candidate),
+		errormargin * cell * output * (1 - output),
+	}, margin * forget
+}
+
+func GRUForward (excitements []float64, state float64) (float64, float64) {
+	update, reset := Sigmoid(excitements[0]), Sigmoid(excitements[1])
+	candidate := math.Tanh(excitements[2] + reset * excitements[3])
+	output := (1 - update) * candidate + update * state
+	return output, output
+}
+
+func GRUBackward (excitements []float64, state float64, errormargin float64, carry float64) ([]float64, float64) {
+	update, reset := Sigmoid(excitements[0]), Sigmoid(excitements[1])
+	candidate := math.Tanh(excitements[2] + reset * excitements[3])
+	margin := errormargin + carry
+	delta := margin * (1 - update) * (1 - candidate * candidate)
+	return []float64 {
+		margin * (state - candidate) * update * (1 - update),
+		delta * excitements[3] * reset * (1 - reset),
+		delta,
+		delta * reset,
+	}, margin * update
+}
+
+func (cell Cell) MarshalJSON () ([]byte, error) {
+	if cell.Name == "" {return json.Marshal("")}
+	if _, ok := Cells[cell.Name]; !ok {return nil, fmt.Errorf("ann: unregistered cell %q", cell.Name)}
+	return json.Marshal(cell.Name)
+}
+
+func (cell *Cell) UnmarshalJSON (data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {return err}
+	if name == "" {
+		*cell = Cell {}
+		return nil
+	}
+	registered, ok := Cells[name]
+	if !ok {return fmt.Errorf("ann: unregistered cell %q", name)}
+	*cell = registered
+	return nil
+}
+
+func recurrence (layers []Layer, l int) []int {
+	if layers[l].Cell.Name == "" {return layers[l].Recurrent}
+	for _, r := range layers[l].Recurrent {
+		if r == l {return layers[l].Recurrent}
+	}
+	return append([]int {l}, layers[l].Recurrent...)
+}
+
+func spans (inputs int, layers []Layer, l int) [][]int {
+	fanin := inputs
+	if l > 0 {fanin = layers[l - 1].Neurons}
+	recurrent := 0
+	for _, r := range recurrence(layers, l) {
+		recurrent = recurrent + layers[r].Neurons
+	}
+	if layers[l].Cell.Name == "" {
+		span := make([]int, fanin + 1 + recurrent)
+		for i := range span {
+			span[i] = i
+		}
+		return [][]int {span}
+	}
+	channels := make([][]int, len(layers[l].Cell.Channels))
+	for c, channel := range layers[l].Cell.Channels {
+		for i := 0; i < fanin + 1 + recurrent; i++ {
+			if (i < fanin && channel.Input) || i == fanin || (i > fanin && channel.Recurrent) {channels[c] = append(channels[c], i)}
+		}
+	}
+	return channels
+}
+
+func GatedCell (cell Cell, learnrate float64, clock chan Tick, inputchans []chan Signal, peripherals SignalPeripherals, deltachans []chan Signal, cancelchan chan struct{}) {
+	var state []float64
+	traces := map[moment]trace {}
+	carries := map[moment][]float64 {}
+	start := 0
+	column := make([]float64, len(inputchans))
+	for {
+		select {
+		case tick := <- clock:
+			if tick.Backward {continue}
+			if tick.Reset {state = nil}
+			if tick.Start {start = tick.Step}
+		case first := <- inputchans[0]:
+			excitements := make([][]float64, len(inputchans))
+			excitements[0] = first.Values
+			for c := 1; c < len(inputchans); c++ {
+				ok, input := PullSignalOrCancel(inputchans[c], cancelchan)
+				if !ok {return}
+				excitements[c] = input.Values
+			}
+			previous := make([]float64, len(first.Values))
+			copy(previous, state)
+			state = make([]float64, len(first.Values))
+			output := Signal {Sample: first.Sample, Step: first.Step, Infer: first.Infer, Values: make([]float64, len(first.Values))}
+			for b := range output.Values {
+				for c := range excitements {
+					column[c] = 0
+					if b < len(excitements[c]) {column[c] = excitements[c][b]}
+				}
+				output.Values[b], state[b] = cell.Forward(column, previous[b])
+			}
+			if !first.Infer {traces[first.moment()] = trace {excitements: excitements, state: previous}}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if devcell {fmt.Printf("\n%v: Cell output step %d %v...\n", time.Now(), output.Step, output.Values)}
+		case errormargin := <- peripherals.Upfeed:
+			key := errormargin.moment()
+			record, carry := traces[key], carries[key]
+			delete(traces, key); delete(carries, key)
+			deltas := make([]Signal, len(inputchans))
+			for c := range deltas {
+				deltas[c] = Signal {Sample: errormargin.Sample, Step: errormargin.Step, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			}
+			margins := make([]float64, len(errormargin.Values))
+			for b, value := range errormargin.Values {
+				var previous, next float64
+				for c := range column {
+					column[c] = 0
+					if c < len(record.excitements) && b < len(record.excitements[c]) {column[c] = record.excitements[c][b]}
+				}
+				if b < len(record.state) {previous = record.state[b]}
+				if b < len(carry) {next = carry[b]}
+				var partials []float64
+				partials, margins[b] = cell.Backward(column, previous, value, next)
+				for c, partial := range partials {
+					deltas[c].Values[b] = partial
+				}
+			}
+			if key.step != start {carries[moment {sample: key.sample, step: key.step - 1}] = margins}
+			for c, delta := range deltas {
+				adjustment := Signal {Sample: delta.Sample, Step: delta.Step, Infer: delta.Infer, Values: make([]float64, len(delta.Values))}
+				for b, value := range delta.Values {
+					adjustment.Values[b] = learnrate * value
+				}
+				if !PushSignalOrCancel(delta, deltachans[c], cancelchan) {return}
+				if !PushSignalOrCancel(adjustment, deltachans[c], cancelchan) {return}
+			}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewCellLayer (layer Layer, upstream []SignalPeripherals, lanes [][]chan Signal, weights [][]float64, spans [][]int, cancelchan chan struct{}) ([]SignalPeripherals, [][]chan Signal, [][][]chan Signal, [][]Probe) {
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	channels := make([][]chan Signal, layer.Neurons)
+	terminals := make([][][]chan Signal, layer.Neurons)
+	probes := make([][]Probe, layer.Neurons)
+	for j := range neurons {
+		neurons[j] = NewSignalPeripherals()
+		channels[j] = make([]chan Signal, len(spans))
+		terminals[j] = make([][]chan Signal, len(spans))
+		for c, span := range spans {
+			channels[j][c] = make(chan Signal)
+			terminals[j][c] = make([]chan Signal, len(span))
+			for s, i := range span {
+				lane := make(chan Signal)
+				lanes[i] = append(lanes[i], lane)
+				terminals[j][c][s] = make(chan Signal)
+				probe := Probe {Read: make(chan float64), Write: make(chan float64)}
+				synapse := SignalPeripherals {Input: lane, Output: channels[j][c], Upfeed: terminals[j][c][s], Downfeed: upstream[i].Upfeed}
+				go TaggedSynapse (weights[j][len(probes[j])], Tag {Source: s, Target: len(lanes[i]) - 1}, synapse, probe, cancelchan)
+				probes[j] = append(probes[j], probe)
+			}
+		}
+	}
+	return neurons, channels, terminals, probes
+}
+
+func NewCellNeuron (learnrate float64, layer Layer, peripherals SignalPeripherals, channels []chan Signal, lanes []chan Signal, terminals [][]chan Signal, fanout Fan, clock chan Tick, faultchan chan Fault, cancelchan chan struct{}) {
+	internals := NewSignalPeripherals()
+	gathered := make([]chan Signal, len(channels))
+	deltas := make([]chan Signal, len(channels))
+	for c := range channels {
+		gathered[c], deltas[c] = make(chan Signal), make(chan Signal)
+		go TaggedDendrite (len(terminals[c]), channels[c], gathered[c], faultchan, cancelchan)
+		go Terminals (deltas[c], terminals[c], cancelchan)
+	}
+
+	go GatedCell (layer.Cell, learnrate, clock, gathered, internals, deltas, cancelchan)
+	go LiveTerminals (internals.Output, lanes, fanout.Lanes, cancelchan)
+	go LiveDendrite (live(lanes), Aggregations["sum"], peripherals.Upfeed, internals.Upfeed, nil, fanout.Live, faultchan, cancelchan)
+}
+
+func (network *Network) spawn (learnrate float64, layer Layer, junction *junction, terminals [][]chan Signal, channels []chan Signal) {
+	if layer.Normalization.Kind != "" || layer.Embedding.Dimensions > 0 {
+		Source(junction.node, junction.lanes, junction.fanout, network.faults, junction.cancelchan)
+		return
+	}
+	if layer.Cell.Name == "" {
+		junction.terminals, junction.fanin = terminals[0], NewFan()
+		NewTerminalNeuron(learnrate, layer, junction.node, junction.lanes, junction.terminals, junction.fanout, junction.fanin, network.faults, junction.cancelchan)
+		return
+	}
+	clock := make(chan Tick)
+	network.clocks, junction.clocks = append(network.clocks, clock), append(junction.clocks, clock)
+	NewCellNeuron(learnrate, layer, junction.node, channels, junction.lanes, terminals, junction.fanout, clock, network.faults, junction.cancelchan)
+}
+
+func (matrix *Matrix) gate (l int, signals []float64, state []float64, output []float64) ([]float64, []float64) {
+	layer := matrix.Layers[l]
+	channels := spans(matrix.Inputs, matrix.Layers, l)
+	excitements := make([]float64, layer.Neurons * len(channels))
+	next := make([]float64, layer.Neurons)
+	for j := 0; j < layer.Neurons; j++ {
+		k := 0
+		for c, span := range channels {
+			for _, i := range span {
+				excitements[j * len(channels) + c] = excitements[j * len(channels) + c] + signals[i] * matrix.Weights[l][j][k]
+				k++
+			}
+		}
+		var previous float64
+		if j < len(state) {previous = state[j]}
+		output[j], next[j] = layer.Cell.Forward(excitements[j * len(channels):(j + 1) * len(channels)], previous)
+	}
+	return excitements, next
+}
+
+func (matrix *Matrix) ungate (l int, signals []float64, excitements []float64, state []float64, errormargins []float64, carry []float64, upstream []float64, adjustments [][]float64) []float64 {
+	layer := matrix.Layers[l]
+	channels := spans(matrix.Inputs, matrix.Layers, l)
+	margins := make([]float64, layer.Neurons)
+	for j, errormargin := range errormargins {
+		var previous, next float64
+		if j < len(state) {previous = state[j]}
+		if j < len(carry) {next = carry[j]}
+		var partials []float64
+		partials, margins[j] = layer.Cell.Backward(excitements[j * len(channels):(j + 1) * len(channels)], previous, errormargin, next)
+		k := 0
+		for c, span := range channels {
+			for _, i := range span {
+				upstream[i] = upstream[i] + partials[c] * matrix.Weights[l][j][k]
+				adjustments[j][k] = adjustments[j][k] + matrix.LearnRate * partials[c] * signals[i]
+				k++
+			}
+		}
+	}
+	return margins
+}
@@ -0,0 +1,125 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Matrix_Cell_Gradients (t *testing.T) {
+	for _, name := range []string {"lstm", "gru"} {
+		layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"], Cell: Cells[name]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+		weights := RandomWeights(1, layers, 41)
+		sequence := bits(6, 45)
+		sequence[2].Expect = nil
+		matrix := NewMatrix(0.1, 1, layers, weights)
+		matrix.LearnSequence(sequence, 0)
+		loss := func (weights [][][]float64) float64 {
+			return SequenceError(sequence, NewMatrix(0.1, 1, layers, weights).PredictSequence(sequence.Inputs()))
+		}
+		for l := range weights {
+			for j := range weights[l] {
+				for i, weight := range weights[l][j] {
+					shifted := copyWeights(weights)
+					shifted[l][j][i] = weight + 1e-6
+					upper := loss(shifted)
+					shifted[l][j][i] = weight - 1e-6
+					lower := loss(shifted)
+					numeric := (upper - lower) / 2e-6
+					analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+					if math.Abs(numeric - analytic) > 1e-6 {
+						t.Log("Failure - Cell backpropagation through time disagrees with finite differences")
+						t.Log(name, l, j, i, analytic, numeric)
+						t.Fail()
+						return
+					}
+				}
+			}
+		}
+	}
+	t.Log("Success - Cell backpropagation through time agrees with finite differences")
+}
+
+func Test_Network_Cell_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 2000
+	topologies := [][]Layer {
+		{{Neurons: 3, Activation: Activations["sigmoid"], Cell: Cells["lstm"]}, {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{{Neurons: 3, Activation: Activations["sigmoid"], Cell: Cells["gru"]}, {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{{Neurons: 2, Activation: Activations["sigmoid"], Cell: Cells["gru"]}, {Neurons: 2, Activation: Activations["sigmoid"], Cell: Cells["lstm"], Recurrent: []int {0}}},
+	}
+
+	go func() {
+		var divergence float64
+		for n, layers := range topologies {
+			weights := RandomWeights(1, layers, int64(n))
+			network, _ := NewNetwork(0.3, 1, layers, weights)
+			matrix := NewMatrix(0.3, 1, layers, weights)
+			sequences := []Sequence {bits(7, 83), bits(4, 54)}
+			sequences[0][1].Expect = nil
+			for epoch := 0; epoch < 5; epoch++ {
+				expected := matrix.LearnSequences(sequences, 3)
+				for s, outputs := range network.LearnSequences(sequences, 3) {
+					for step := range outputs {
+						for j := range outputs[step] {
+							divergence = math.Max(divergence, math.Abs(outputs[step][j] - expected[s][step][j]))
+						}
+					}
+				}
+			}
+			after := network.Weights()
+			for l := range after {
+				for j := range after[l] {
+					for i := range after[l][j] {
+						divergence = math.Max(divergence, math.Abs(after[l][j][i] - matrix.Weights[l][j][i]))
+					}
+				}
+			}
+			regimen := SequenceRegimen {Sequences: sequences}
+			divergence = math.Max(divergence, math.Abs(network.SequenceLoss(regimen) - matrix.SequenceLoss(regimen)))
+			network.Close()
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Cell network sequence learning timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Cell network diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Cell network matches the matrix engine")
+		return
+	}
+}
+
+func Test_Model_Cell_Roundtrip (t *testing.T) {
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"], Cell: Cells["lstm"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	matrix := NewMatrix(0.1, 1, layers, RandomWeights(1, layers, 7))
+	var buffer bytes.Buffer
+	matrix.Save(&buffer)
+	model, err := LoadModel(&buffer)
+	if err != nil || model.Layers[0].Cell.Name != "lstm" || model.Layers[0].Cell.Forward == nil || model.Layers[1].Cell.Name != "" {
+		t.Log("Failure - Cell lost in the saved model")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	inputs := [][]float64 {{1}, {0}, {1}}
+	expected := matrix.PredictSequence(inputs)
+	for step, output := range model.Matrix().PredictSequence(inputs) {
+		if math.Abs(output[0] - expected[step][0]) > 1e-12 {
+			t.Log("Failure - Loaded cell model predicts differently")
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Cell survives a save and load")
+}
@@ -0,0 +1,110 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type Convolution struct {
+	Width int
+	Height int
+	Channels int
+	Kernel int
+	Stride int
+	Padding int
+	Filters int
+	Pooling bool
+}
+
+func ConvolutionLayer (width int, height int, channels int, kernel int, stride int, padding int, filters int, activation Activation) Layer {
+	convolution := Convolution {Width: width, Height: height, Channels: channels, Kernel: kernel, Stride: stride, Padding: padding, Filters: filters}
+	columns, rows := convolution.Outputs()
+	return Layer {Neurons: columns * rows * filters, Activation: activation, Convolution: convolution}
+}
+
+func PoolingLayer (width int, height int, channels int, size int, stride int, aggregation Aggregation) Layer {
+	convolution := Convolution {Width: width, Height: height, Channels: channels, Kernel: size, Stride: stride, Filters: channels, Pooling: true}
+	columns, rows := convolution.Outputs()
+	return Layer {Neurons: columns * rows * channels, Activation: Activations["identity"], Aggregation: aggregation, Convolution: convolution}
+}
+
+func (convolution Convolution) stride () int {
+	if convolution.Stride <= 0 {return 1}
+	return convolution.Stride
+}
+
+func (convolution Convolution) Outputs () (int, int) {
+	stride := convolution.stride()
+	return (convolution.Width + 2 * convolution.Padding - convolution.Kernel) / stride + 1, (convolution.Height + 2 * convolution.Padding - convolution.Kernel) / stride + 1
+}
+
+func (convolution Convolution) Parameters () (int, int) {
+	if convolution.Pooling {return 0, 0}
+	return convolution.Filters, convolution.Channels * convolution.Kernel * convolution.Kernel + 1
+}
+
+func CheckConvolutions (inputs int, layers []Layer) error {
+	for l, layer := range layers {
+		convolution := layer.Convolution
+		if convolution.Kernel <= 0 {continue}
+		fanin := inputs
+		if l > 0 {fanin = layers[l - 1].Neurons}
+		if convolution.Width <= 0 || convolution.Height <= 0 || convolution.Channels <= 0 || convolution.Filters <= 0 || convolution.Padding < 0 {
+			return fmt.Errorf("ann: layer %d has an empty %dx%dx%d convolution with %d filters and padding %d", l, convolution.Width, convolution.Height, convolution.Channels, convolution.Filters, convolution.Padding)
+		}
+		if convolution.Width * convolution.Height * convolution.Channels != fanin {
+			return fmt.Errorf("ann: layer %d convolves a %dx%dx%d input but has %d inputs", l, convolution.Width, convolution.Height, convolution.Channels, fanin)
+		}
+		width, height := convolution.Width + 2 * convolution.Padding, convolution.Height + 2 * convolution.Padding
+		if convolution.Kernel > width || convolution.Kernel > height || convolution.stride() > width || convolution.stride() > height {
+			return fmt.Errorf("ann: layer %d has kernel %d and stride %d over a padded %dx%d input", l, convolution.Kernel, convolution.stride(), width, height)
+		}
+		if convolution.Pooling && convolution.Filters != convolution.Channels {
+			return fmt.Errorf("ann: layer %d pools %d channels into %d", l, convolution.Channels, convolution.Filters)
+		}
+		columns, rows := convolution.Outputs()
+		if layer.Neurons != columns * rows * convolution.Filters {
+			return fmt.Errorf("ann: layer %d has %d neurons but its convolution has %d outputs", l, layer.Neurons, columns * rows * convolution.Filters)
+		}
+	}
+	return nil
+}
+
+func convolutionConnections (inputs int, layers []Layer, l int) [][]Connection {
+	convolution := layers[l].Convolution
+	fanin := inputs
+	if l > 0 {fanin = layers[l - 1].Neurons}
+	columns, rows := convolution.Outputs()
+	stride := convolution.stride()
+	links := make([][]Connection, layers[l].Neurons)
+	for j := range links {
+		f, y, x := j / (columns * rows), j / columns % rows, j % columns
+		for c := 0; c < convolution.Channels; c++ {
+			if convolution.Pooling && c != f {continue}
+			for ky := 0; ky < convolution.Kernel; ky++ {
+				for kx := 0; kx < convolution.Kernel; kx++ {
+					iy, ix := y * stride - convolution.Padding + ky, x * stride - convolution.Padding + kx
+					if iy < 0 || iy >= convolution.Height || ix < 0 || ix >= convolution.Width {continue}
+					link := Connection {Input: (c * convolution.Height + iy) * convolution.Width + ix, Layer: l, Row: f, Column: (c * convolution.Kernel + ky) * convolution.Kernel + kx}
+					if convolution.Pooling {link.Row, link.Column = -1, -1}
+					links[j] = append(links[j], link)
+				}
+			}
+		}
+		if !convolution.Pooling {links[j] = append(links[j], Connection {Input: fanin, Layer: l, Row: f, Column: convolution.Channels * convolution.Kernel * convolution.Kernel})}
+	}
+	return links
+}
+
+func convolutionWeights (convolution Convolution, random *rand.Rand) [][]float64 {
+	rows, columns := convolution.Parameters()
+	weights := make([][]float64, rows)
+	for f := range weights {
+		weights[f] = make([]float64, columns)
+		for i := range weights[f] {
+			weights[f][i] = (random.Float64()*2 - 1) / math.Sqrt(float64(columns))
+		}
+	}
+	return weights
+}
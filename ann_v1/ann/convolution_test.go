@@ -0,0 +1,162 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func convolutionLayers () []Layer {
+	return []Layer {
+		ConvolutionLayer(5, 5, 2, 3, 1, 1, 2, Activations["sigmoid"]),
+		PoolingLayer(5, 5, 2, 2, 2, Aggregations["max"]),
+		ConvolutionLayer(2, 2, 2, 2, 1, 0, 3, Activations["sigmoid"]),
+		{Neurons: 2, Activation: Activations["sigmoid"]},
+	}
+}
+
+func image (seed int) []float64 {
+	pixels := make([]float64, 50)
+	for i := range pixels {
+		pixels[i] = math.Mod(float64(i * seed) * 0.37, 1)
+	}
+	return pixels
+}
+
+func Test_Convolution_Connections (t *testing.T) {
+	layers := convolutionLayers()
+	if layers[0].Neurons != 50 || layers[1].Neurons != 8 || layers[2].Neurons != 3 {
+		t.Log("Failure - Convolution output shapes are wrong")
+		t.Log(layers[0].Neurons, layers[1].Neurons, layers[2].Neurons)
+		t.Fail()
+		return
+	}
+	links := connections(50, layers, 0)
+	if len(links[0]) != 2 * 4 + 1 || len(links[12]) != 2 * 9 + 1 || links[12][18].Input != 50 || links[12][18].Column != 18 {
+		t.Log("Failure - Convolution connections ignore padding or bias")
+		t.Log(len(links[0]), len(links[12]), links[12][18])
+		t.Fail()
+		return
+	}
+	for _, link := range connections(50, layers, 1)[7] {
+		if link.Row != -1 || link.Input < 25 {
+			t.Log("Failure - Pooling connections cross channels or carry weights")
+			t.Log(link)
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Convolution connections follow kernel, stride and padding")
+}
+
+func Test_Matrix_Convolution_Gradients (t *testing.T) {
+	layers := convolutionLayers()
+	weights := RandomWeights(50, layers, 13)
+	set := TrainingSet {Input: image(7), Expect: []float64 {1, 0}}
+	matrix := NewMatrix(0.1, 50, layers, weights)
+	matrix.Learn(set)
+	loss := func (weights [][][]float64) float64 {
+		return SquaredError(set.Expect, NewMatrix(0.1, 50, layers, weights).Predict(set.Input))
+	}
+	for l := range weights {
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				shifted := copyWeights(weights)
+				shifted[l][j][i] = weight + 1e-6
+				upper := loss(shifted)
+				shifted[l][j][i] = weight - 1e-6
+				lower := loss(shifted)
+				numeric := (upper - lower) / 2e-6
+				analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+				if math.Abs(numeric - analytic) > 1e-6 {
+					t.Log("Failure - Shared weight gradients disagree with finite differences")
+					t.Log(l, j, i, analytic, numeric)
+					t.Fail()
+					return
+				}
+			}
+		}
+	}
+	t.Log("Success - Shared weight gradients agree with finite differences")
+}
+
+func Test_Network_Convolution_Geometry (t *testing.T) {
+	dense := Layer {Neurons: 2, Activation: Activations["sigmoid"]}
+	bad := map[string][]Layer {
+		"fan-in": {ConvolutionLayer(5, 5, 1, 3, 1, 1, 2, Activations["sigmoid"]), dense},
+		"kernel": {ConvolutionLayer(5, 5, 2, 6, 1, 0, 2, Activations["sigmoid"]), dense},
+		"stride": {ConvolutionLayer(5, 5, 2, 3, 6, 0, 2, Activations["sigmoid"]), dense},
+		"neurons": {{Neurons: 3, Activation: Activations["sigmoid"], Convolution: Convolution {Width: 5, Height: 5, Channels: 2, Kernel: 3, Filters: 2}}, dense},
+	}
+	for name, layers := range bad {
+		if network, err := NewNetwork(0.5, 50, layers, nil); err == nil {
+			network.Close()
+			t.Log("Failure - Network accepted a convolution with bad " + name)
+			t.Fail()
+			return
+		}
+	}
+	if err := CheckConvolutions(50, convolutionLayers()); err != nil {
+		t.Log("Failure - Convolution check rejected valid geometry")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Network rejects bad convolution geometry")
+}
+
+func Test_Network_Convolution_Matrix (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 2000
+	layers := convolutionLayers()
+	weights := RandomWeights(50, layers, 17)
+	network, _ := NewNetwork(0.5, 50, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 50, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: image(3), Expect: []float64 {0, 1}},
+		{Input: image(11), Expect: []float64 {1, 0}},
+	}}
+
+	go func() {
+		var divergences []Divergence
+		for epoch := 0; epoch < 5; epoch++ {
+			divergences = append(divergences, CrossCheck(network, matrix, regimen)...)
+			expected := matrix.LearnBatch(regimen.TrainingSets)
+			for s, output := range network.LearnBatch(regimen.TrainingSets) {
+				for j := range output {
+					divergences = append(divergences, Divergence {Sample: s, Output: math.Abs(output[j] - expected[s][j])})
+				}
+			}
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Convolution cross check timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Convolution network diverged from the matrix engine")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		var buffer bytes.Buffer
+		network.Save(&buffer)
+		model, err := LoadModel(&buffer)
+		if err != nil || model.Layers[0].Convolution != layers[0].Convolution || !model.Layers[1].Convolution.Pooling || len(model.Weights[0]) != 2 || len(model.Weights[1]) != 0 {
+			t.Log("Failure - Convolution lost in the saved model")
+			t.Log(err)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Convolution network matches the matrix engine")
+		return
+	}
+}
@@ -0,0 +1,185 @@
+package ann
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+)
+
+const devsource bool = false
+
+type DataSource interface {
+	Next () (TrainingSet, bool)
+	Reset () error
+	Len () int
+}
+
+type RandomSource struct {
+	regimen Regimen
+	random *rand.Rand
+	drawn int
+}
+
+type SequentialSource struct {
+	regimen Regimen
+	position int
+}
+
+type ShuffledSource struct {
+	regimen Regimen
+	seed int64
+	epoch int64
+	order []int
+	position int
+}
+
+type ReaderSource struct {
+	reader io.Reader
+	records *csv.Reader
+	inputs int
+	err error
+}
+
+type ChannelSource struct {
+	channel chan TrainingSet
+}
+
+func NewRandomSource (regimen Regimen, seed int64) *RandomSource {
+	return &RandomSource {regimen: regimen, random: rand.New(rand.NewSource(seed))}
+}
+
+func (source *RandomSource) Next () (TrainingSet, bool) {
+	if source.drawn >= len(source.regimen.TrainingSets) {return TrainingSet {}, false}
+	source.drawn++
+	return source.regimen.TrainingSets[source.random.Intn(len(source.regimen.TrainingSets))], true
+}
+
+func (source *RandomSource) Reset () error {
+	source.drawn = 0
+	return nil
+}
+
+func (source *RandomSource) Len () int {return len(source.regimen.TrainingSets)}
+
+func NewSequentialSource (regimen Regimen) *SequentialSource {
+	return &SequentialSource {regimen: regimen}
+}
+
+func (source *SequentialSource) Next () (TrainingSet, bool) {
+	if source.position >= len(source.regimen.TrainingSets) {return TrainingSet {}, false}
+	source.position++
+	return source.regimen.TrainingSets[source.position - 1], true
+}
+
+func (source *SequentialSource) Reset () error {
+	source.position = 0
+	return nil
+}
+
+func (source *SequentialSource) Len () int {return len(source.regimen.TrainingSets)}
+
+func NewShuffledSource (regimen Regimen, seed int64) *ShuffledSource {
+	source := &ShuffledSource {regimen: regimen, seed: seed}
+	source.order = rand.New(rand.NewSource(seed)).Perm(len(regimen.TrainingSets))
+	return source
+}
+
+func (source *ShuffledSource) Next () (TrainingSet, bool) {
+	if source.position >= len(source.order) {return TrainingSet {}, false}
+	source.position++
+	return source.regimen.TrainingSets[source.order[source.position - 1]], true
+}
+
+func (source *ShuffledSource) Reset () error {
+	source.epoch++
+	source.order = rand.New(rand.NewSource(source.seed + source.epoch)).Perm(len(source.regimen.TrainingSets))
+	source.position = 0
+	return nil
+}
+
+func (source *ShuffledSource) Len () int {return len(source.regimen.TrainingSets)}
+
+func NewReaderSource (reader io.Reader, inputs int) *ReaderSource {
+	return &ReaderSource {reader: reader, records: csv.NewReader(reader), inputs: inputs}
+}
+
+func OpenFileSource (path string, inputs int) (*ReaderSource, error) {
+	file, err := os.Open(path)
+	if err != nil {return nil, err}
+	return NewReaderSource(file, inputs), nil
+}
+
+func (source *ReaderSource) Next () (TrainingSet, bool) {
+	if source.err != nil {return TrainingSet {}, false}
+	record, err := source.records.Read()
+	if err != nil {
+		if err != io.EOF {source.err = err}
+		return TrainingSet {}, false
+	}
+	if len(record) < source.inputs {
+		source.err = fmt.Errorf("ann: record has %d fields, expected at least %d inputs", len(record), source.inputs)
+		return TrainingSet {}, false
+	}
+	values := make([]float64, len(record))
+	for v, field := range record {
+		if values[v], err = strconv.ParseFloat(field, 64); err != nil {
+			source.err = err
+			return TrainingSet {}, false
+		}
+	}
+	return TrainingSet {Input: values[:source.inputs], Expect: values[source.inputs:]}, true
+}
+
+func (source *ReaderSource) Reset () error {
+	seeker, ok := source.reader.(io.Seeker)
+	if !ok {return fmt.Errorf("ann: reader source cannot rewind a non-seekable reader")}
+	if _, source.err = seeker.Seek(0, io.SeekStart); source.err == nil {
+		source.records = csv.NewReader(source.reader)
+	}
+	return source.err
+}
+
+func (source *ReaderSource) Len () int {return -1}
+
+func (source *ReaderSource) Err () error {return source.err}
+
+func (source *ReaderSource) Close () error {
+	if closer, ok := source.reader.(io.Closer); ok {return closer.Close()}
+	return nil
+}
+
+func failure (source DataSource) error {
+	if failing, ok := source.(interface {Err () error}); ok {return failing.Err()}
+	return nil
+}
+
+func NewChannelSource (channel chan TrainingSet) *ChannelSource {
+	return &ChannelSource {channel: channel}
+}
+
+func (source *ChannelSource) Next () (TrainingSet, bool) {
+	set, ok := <- source.channel
+	return set, ok
+}
+
+func (source *ChannelSource) Reset () error {return fmt.Errorf("ann: channel source cannot rewind")}
+
+func (source *ChannelSource) Len () int {return -1}
+
+func Produce (source DataSource, cancelchan chan struct{}) chan TrainingSet {
+	channel := make(chan TrainingSet)
+	go func () {
+		defer close(channel)
+		for set, ok := source.Next(); ok; set, ok = source.Next() {
+			select {
+			case channel <- set:
+			case <- cancelchan:
+				return
+			}
+		}
+	}()
+	return channel
+}
@@ -0,0 +1,161 @@
+package ann
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_SequentialSource_Order (t *testing.T) {
+	source := NewSequentialSource(labelled(5, 2))
+	for pass := 0; pass < 2; pass++ {
+		count := 0
+		for set, ok := source.Next(); ok; set, ok = source.Next() {
+			if set.Input[0] != float64(count) {
+				t.Log("Failure - Sequential source out of order")
+				t.Fail()
+				return
+			}
+			count++
+		}
+		if count != source.Len() {
+			t.Log("Failure - Sequential source length inaccurate")
+			t.Fail()
+			return
+		}
+		source.Reset()
+	}
+	t.Log("Success - Sequential source is ordered")
+}
+
+func Test_ShuffledSource_Epochs (t *testing.T) {
+	first := NewShuffledSource(labelled(10, 2), 4); second := NewShuffledSource(labelled(10, 2), 4)
+	var epochs [2][]float64
+	for epoch := range epochs {
+		seen := map[float64]bool {}
+		for set, ok := first.Next(); ok; set, ok = first.Next() {
+			other, _ := second.Next()
+			if set.Input[0] != other.Input[0] || seen[set.Input[0]] {
+				t.Log("Failure - Shuffled source is not a seeded permutation")
+				t.Fail()
+				return
+			}
+			seen[set.Input[0]] = true
+			epochs[epoch] = append(epochs[epoch], set.Input[0])
+		}
+		first.Reset(); second.Reset()
+	}
+	for i := range epochs[0] {
+		if epochs[0][i] != epochs[1][i] {
+			t.Log("Success - Shuffled source reshuffles every epoch")
+			return
+		}
+	}
+	t.Log("Failure - Shuffled source repeats its epoch order")
+	t.Fail()
+}
+
+func Test_ReaderSource_Parse (t *testing.T) {
+	source := NewReaderSource(strings.NewReader("1,2,3\n4,5,6\n"), 2)
+	set, ok := source.Next()
+	if !ok || len(set.Input) != 2 || set.Input[1] != 2 || set.Expect[0] != 3 {
+		t.Log("Failure - Reader source parsed inaccurately")
+		t.Fail()
+		return
+	}
+	source.Next()
+	if _, ok := source.Next(); ok || source.Err() != nil {
+		t.Log("Failure - Reader source did not end cleanly")
+		t.Fail()
+		return
+	}
+	source.Reset()
+	if set, ok := source.Next(); !ok || set.Input[0] != 1 {
+		t.Log("Failure - Reader source did not rewind")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Reader source parses records")
+}
+
+func Test_ReaderSource_Errors (t *testing.T) {
+	source := NewReaderSource(strings.NewReader("1,2,3\n4\n"), 2)
+	source.Next()
+	if _, ok := source.Next(); ok || source.Err() == nil {
+		t.Log("Failure - Reader source accepted a record without enough inputs")
+		t.Fail()
+		return
+	}
+	streamed := NewReaderSource(bufio.NewReader(strings.NewReader("1,2,3\n")), 2)
+	if streamed.Reset() == nil {
+		t.Log("Failure - Reader source rewound a non-seekable reader")
+		t.Fail()
+		return
+	}
+	layers := []Layer {{Neurons: 1, Activation: Activations["identity"]}}
+	matrix := NewMatrix(0.1, 2, layers, RandomWeights(2, layers, 1))
+	if matrix.Feed(NewReaderSource(bufio.NewReader(strings.NewReader("1,2,3\n")), 2), 2) == nil || matrix.Feed(NewReaderSource(strings.NewReader("1,2,3\n4\n"), 2), 1) == nil {
+		t.Log("Failure - Feeding did not surface the reader source error")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Reader source reports short records and non-seekable rewinds")
+}
+
+func Test_ChannelSource_Workflow (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan int)
+	var timeout time.Duration = 10
+	source := NewChannelSource(Produce(NewSequentialSource(labelled(3, 2)), cancelchan))
+
+	go func() {
+		count := 0
+		for _, ok := source.Next(); ok; _, ok = source.Next() {
+			count++
+		}
+		resultchan <- count
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Channel source workflow timed out")
+		t.Fail()
+		return
+	case count := <- resultchan:
+		if count != 3 {
+			t.Log("Failure - Channel source lost training sets")
+			t.Fail()
+			return
+		}
+		t.Log("Success - Channel source workflow is clear")
+		return
+	}
+}
+
+func Test_SourceInput_EmptyInput (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan bool)
+	var timeout time.Duration = 10
+	peripherals := NewSignalPeripherals()
+	source := NewSequentialSource(Regimen {TrainingSets: []TrainingSet {{Expect: []float64 {1}}}})
+
+	go func() {
+		SourceInput(1, source, peripherals, make(chan Signal), cancelchan)
+		_, open := <- cancelchan
+		resultchan <- open
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Source input blocked on a set without inputs")
+		t.Fail()
+		return
+	case open := <- resultchan:
+		if open {
+			t.Log("Failure - Source input did not end the session on a set without inputs")
+			t.Fail()
+			return
+		}
+		t.Log("Success - Source input rejects sets without inputs")
+		return
+	}
+}
@@ -0,0 +1,263 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const devembedding bool = false
+
+type Embedding struct {
+	Columns []int
+	Categories []int
+	Dimensions int
+}
+
+type Table struct {
+	Read chan [][]float64
+	Write chan [][]float64
+}
+
+func NewTable () Table {
+	return Table {Read: make(chan [][]float64), Write: make(chan [][]float64)}
+}
+
+func EmbeddingLayer (inputs int, columns []int, categories []int, dimensions int) Layer {
+	embedding := Embedding {Columns: columns, Categories: categories, Dimensions: dimensions}
+	return Layer {Neurons: inputs + len(columns) * (dimensions - 1), Activation: Activations["identity"], Embedding: embedding}
+}
+
+func CheckEmbeddings (inputs int, layers []Layer, weights [][][]float64) error {
+	for l, layer := range layers {
+		embedding := layer.Embedding
+		if embedding.Dimensions <= 0 {continue}
+		fanin := inputs
+		if l > 0 {fanin = layers[l - 1].Neurons}
+		if len(embedding.Columns) != len(embedding.Categories) {
+			return fmt.Errorf("ann: layer %d embeds %d columns but counts categories for %d", l, len(embedding.Columns), len(embedding.Categories))
+		}
+		rows := 0
+		seen := map[int]bool {}
+		for c, column := range embedding.Columns {
+			if column < 0 || column >= fanin || seen[column] {return fmt.Errorf("ann: layer %d embeds column %d of %d inputs more than once or out of range", l, column, fanin)}
+			if embedding.Categories[c] <= 0 {return fmt.Errorf("ann: layer %d embeds column %d with %d categories", l, column, embedding.Categories[c])}
+			seen[column], rows = true, rows + embedding.Categories[c]
+		}
+		if layer.Neurons != fanin + len(embedding.Columns) * (embedding.Dimensions - 1) {
+			return fmt.Errorf("ann: layer %d has %d neurons but its embedding has %d outputs", l, layer.Neurons, fanin + len(embedding.Columns) * (embedding.Dimensions - 1))
+		}
+		if l >= len(weights) || len(weights[l]) != rows {
+			return fmt.Errorf("ann: layer %d needs an embedding table of %d rows", l, rows)
+		}
+		for r, row := range weights[l] {
+			if len(row) != embedding.Dimensions {return fmt.Errorf("ann: layer %d embedding row %d has %d dimensions, not %d", l, r, len(row), embedding.Dimensions)}
+		}
+	}
+	return nil
+}
+
+func (embedding Embedding) encoded () map[int]int {
+	encoded := map[int]int {}
+	for c, column := range embedding.Columns {
+		encoded[column] = c
+	}
+	return encoded
+}
+
+func (embedding Embedding) row (c int, id float64) int {
+	category := int(id)
+	if float64(category) != id || category < 0 || category >= embedding.Categories[c] {return -1}
+	for _, categories := range embedding.Categories[:c] {
+		category = category + categories
+	}
+	return category
+}
+
+func (embedding Embedding) unknown (input []float64) []int {
+	var columns []int
+	for c, column := range embedding.Columns {
+		if column < len(input) && embedding.row(c, input[column]) < 0 {columns = append(columns, column)}
+	}
+	return columns
+}
+
+func (embedding Embedding) shaped (table [][]float64, rows int) bool {
+	if len(table) != rows {return false}
+	for _, row := range table {
+		if len(row) != embedding.Dimensions {return false}
+	}
+	return true
+}
+
+func (embedding Embedding) Lookup (table [][]float64, input []float64) []float64 {
+	var output []float64
+	encoded := embedding.encoded()
+	for i, value := range input {
+		c, ok := encoded[i]
+		if !ok {
+			output = append(output, value)
+			continue
+		}
+		if row := embedding.row(c, value); row >= 0 {
+			output = append(output, table[row]...)
+		} else {
+			output = append(output, make([]float64, embedding.Dimensions)...)
+		}
+	}
+	return output
+}
+
+func (embedding Embedding) Gradients (input []float64, errormargins []float64) ([]float64, map[int][]float64) {
+	upstream := make([]float64, len(input))
+	gradients := map[int][]float64 {}
+	encoded := embedding.encoded()
+	k := 0
+	for i, value := range input {
+		c, ok := encoded[i]
+		if !ok {
+			upstream[i] = errormargins[k]
+			k++
+			continue
+		}
+		if row := embedding.row(c, value); row >= 0 {gradients[row] = errormargins[k:k + embedding.Dimensions]}
+		k = k + embedding.Dimensions
+	}
+	return upstream, gradients
+}
+
+func embeddingWeights (embedding Embedding, random *rand.Rand) [][]float64 {
+	var weights [][]float64
+	for _, categories := range embedding.Categories {
+		for r := 0; r < categories; r++ {
+			row := make([]float64, embedding.Dimensions)
+			for d := range row {
+				row[d] = (random.Float64()*2 - 1) / math.Sqrt(float64(embedding.Dimensions))
+			}
+			weights = append(weights, row)
+		}
+	}
+	return weights
+}
+
+func Embedder (embedding Embedding, learnrate float64, weights [][]float64, table Table, inputchan chan Signal, marginchan chan Signal, outputchans []chan Signal, downfeeds []chan Signal, targets []int, faultchan chan Fault, cancelchan chan struct{}) {
+	inputs, margins := map[moment][]Signal {}, map[moment][]Signal {}
+	records := map[moment][][]float64 {}
+	pending := map[int][]float64 {}
+	for {
+		select {
+		case input := <- inputchan:
+			arrived, ok := gather(inputs, input, len(downfeeds))
+			if !ok {continue}
+			rows := Rows(arrived)
+			if !input.Infer {records[input.moment()] = rows}
+			outputs := make([][]float64, len(rows))
+			for b, row := range rows {
+				for _, column := range embedding.unknown(row) {
+					ReportFault(Fault {Kind: "category", Sample: input.Sample, Step: input.Step, Source: column}, faultchan)
+				}
+				outputs[b] = embedding.Lookup(weights, row)
+			}
+			for j, output := range Transpose(outputs, len(outputchans)) {
+				output.Sample, output.Step, output.Infer = input.Sample, input.Step, input.Infer
+				if !PushSignalOrCancel(output, outputchans[j], cancelchan) {return}
+			}
+			if devembedding {fmt.Printf("\n%v: Embedder relayed sample %d...\n", time.Now(), input.Sample)}
+		case margin := <- marginchan:
+			arrived, ok := gather(margins, margin, len(outputchans))
+			if !ok {continue}
+			rows := records[margin.moment()]
+			delete(records, margin.moment())
+			upstream := make([][]float64, len(rows))
+			for b, errormargins := range Rows(arrived) {
+				var gradients map[int][]float64
+				upstream[b], gradients = embedding.Gradients(rows[b], errormargins)
+				for row, gradient := range gradients {
+					if pending[row] == nil {pending[row] = make([]float64, embedding.Dimensions)}
+					for d, value := range gradient {
+						pending[row][d] = pending[row][d] + learnrate * value
+					}
+				}
+			}
+			for i, signal := range Transpose(upstream, len(downfeeds)) {
+				signal.Sample, signal.Step, signal.Source = margin.Sample, margin.Step, targets[i]
+				if !PushSignalOrCancel(signal, downfeeds[i], cancelchan) {return}
+			}
+			if len(records) > 0 {continue}
+			for row, adjustment := range pending {
+				updated := append([]float64 {}, weights[row]...)
+				for d := range updated {
+					updated[d] = updated[d] + adjustment[d]
+				}
+				weights[row] = updated
+			}
+			if devembedding {fmt.Printf("\n%v: Embedder updated %d rows...\n", time.Now(), len(pending))}
+			pending = map[int][]float64 {}
+		case table.Read <- weights:
+			weights = append([][]float64 {}, weights...)
+		case written := <- table.Write:
+			if !embedding.shaped(written, len(weights)) {
+				ReportFault(Fault {Kind: "table", Sample: -1, Step: -1, Source: -1}, faultchan)
+				continue
+			}
+			weights = copyWeights([][][]float64 {written})[0]
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewEmbeddingLayer (layer Layer, learnrate float64, upstream []SignalPeripherals, lanes [][]chan Signal, weights [][]float64, faultchan chan Fault, cancelchan chan struct{}) ([]SignalPeripherals, Table) {
+	inputchan, marginchan := make(chan Signal), make(chan Signal)
+	downfeeds := make([]chan Signal, len(upstream))
+	targets := make([]int, len(upstream))
+	for i, source := range upstream {
+		lane := make(chan Signal)
+		lanes[i] = append(lanes[i], lane)
+		targets[i], downfeeds[i] = len(lanes[i]) - 1, source.Upfeed
+		go Label (i, lane, inputchan, cancelchan)
+	}
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	outputchans := make([]chan Signal, layer.Neurons)
+	for j := range neurons {
+		neurons[j] = NewSignalPeripherals()
+		outputchans[j] = neurons[j].Input
+		go Label (j, neurons[j].Downfeed, marginchan, cancelchan)
+	}
+	table := NewTable()
+	go Embedder (layer.Embedding, learnrate, copyWeights([][][]float64 {weights})[0], table, inputchan, marginchan, outputchans, downfeeds, targets, faultchan, cancelchan)
+	return neurons, table
+}
+
+func (matrix *Matrix) adjustments () [][][]float64 {
+	adjustments := make([][][]float64, len(matrix.Weights))
+	for l := range matrix.Weights {
+		if matrix.Layers[l].Embedding.Dimensions > 0 {
+			adjustments[l] = make([][]float64, len(matrix.Weights[l]))
+			continue
+		}
+		adjustments[l] = zeroWeights(matrix.Weights[l:l + 1])[0]
+	}
+	return adjustments
+}
+
+func (matrix *Matrix) embed (l int, signal []float64, output []float64) {
+	fanin := matrix.Inputs
+	if l > 0 {fanin = matrix.Layers[l - 1].Neurons}
+	copy(output, matrix.Layers[l].Embedding.Lookup(matrix.Weights[l], signal[:fanin]))
+}
+
+func (matrix *Matrix) unembed (l int, signal []float64, errormargins []float64, upstream []float64, adjustments [][]float64) {
+	embedding := matrix.Layers[l].Embedding
+	fanin := matrix.Inputs
+	if l > 0 {fanin = matrix.Layers[l - 1].Neurons}
+	margins, gradients := embedding.Gradients(signal[:fanin], errormargins)
+	copy(upstream, margins)
+	for row, gradient := range gradients {
+		if adjustments[row] == nil {adjustments[row] = make([]float64, embedding.Dimensions)}
+		for d, value := range gradient {
+			adjustments[row][d] = adjustments[row][d] + matrix.LearnRate * value
+		}
+	}
+}
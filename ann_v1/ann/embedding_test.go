@@ -0,0 +1,189 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func embeddingLayers () []Layer {
+	return []Layer {
+		EmbeddingLayer(3, []int {0, 2}, []int {6, 4}, 2),
+		{Neurons: 3, Activation: Activations["sigmoid"]},
+		{Neurons: 1, Activation: Activations["sigmoid"]},
+	}
+}
+
+var embeddingSets = []TrainingSet {
+	{Input: []float64 {1, 0.5, 3}, Expect: []float64 {1}},
+	{Input: []float64 {4, 0.2, 0}, Expect: []float64 {0}},
+	{Input: []float64 {1, 0.9, 0}, Expect: []float64 {1}},
+}
+
+func Test_Embedding_Lookup (t *testing.T) {
+	layer := embeddingLayers()[0]
+	table := RandomWeights(3, embeddingLayers(), 1)[0]
+	output := layer.Embedding.Lookup(table, []float64 {5, 0.7, 2})
+	if layer.Neurons != 5 || len(table) != 10 || len(output) != 5 || output[0] != table[5][0] || output[2] != 0.7 || output[4] != table[8][1] {
+		t.Log("Failure - Embedding lookup misplaced the category vectors")
+		t.Log(layer.Neurons, len(table), output)
+		t.Fail()
+		return
+	}
+	if unknown := layer.Embedding.Lookup(table, []float64 {6, 0.7, 1.5}); unknown[0] != 0 || unknown[1] != 0 || unknown[3] != 0 || unknown[4] != 0 {
+		t.Log("Failure - Embedding lookup returned a vector for an unknown category")
+		t.Log(unknown)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Embedding lookup expands category ids in place")
+}
+
+func Test_Network_Embedding_Faults (t *testing.T) {
+	resultchan := make(chan []Fault)
+	var timeout time.Duration = 500
+	layers := embeddingLayers()
+	weights := RandomWeights(3, layers, 5)
+	narrow := copyWeights(weights)
+	narrow[0][3] = narrow[0][3][:1]
+	short := copyWeights(weights)
+	short[0] = short[0][:9]
+	for _, table := range [][][][]float64 {narrow, short} {
+		if network, err := NewNetwork(0.5, 3, layers, table); err == nil {
+			network.Close()
+			t.Log("Failure - Network accepted an embedding table of the wrong shape")
+			t.Fail()
+			return
+		}
+	}
+	network, _ := NewNetwork(0.5, 3, layers, weights)
+	defer network.Close()
+
+	go func() {
+		network.Predict([]float64 {7, 0.5, 1})
+		network.SetWeights(narrow)
+		var faults []Fault
+		for len(faults) < 2 {
+			faults = append(faults, <- network.Faults())
+		}
+		if after := network.Weights(); len(after[0][3]) != 2 {faults = nil}
+		resultchan <- faults
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Embedding faults timed out")
+		t.Fail()
+		return
+	case faults := <- resultchan:
+		if len(faults) != 2 || faults[0].Kind != "category" || faults[0].Source != 0 || faults[1].Kind != "table" {
+			t.Log("Failure - Embedding did not report an unknown category and a malformed table")
+			t.Log(faults)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Embedding reports unknown categories and malformed tables")
+		return
+	}
+}
+
+func Test_Matrix_Embedding_Sparse (t *testing.T) {
+	layers := embeddingLayers()
+	weights := RandomWeights(3, layers, 7)
+	matrix := NewMatrix(0.1, 3, layers, weights)
+	matrix.LearnBatch(embeddingSets)
+	seen := map[int]bool {1: true, 4: true, 6: true, 9: true}
+	for row := range weights[0] {
+		changed := matrix.Weights[0][row][0] != weights[0][row][0] || matrix.Weights[0][row][1] != weights[0][row][1]
+		if changed != seen[row] {
+			t.Log("Failure - Embedding updated rows outside the batch")
+			t.Log(row, changed)
+			t.Fail()
+			return
+		}
+	}
+	loss := func (weights [][][]float64) float64 {
+		var sum float64
+		for _, set := range embeddingSets {
+			sum = sum + SquaredError(set.Expect, NewMatrix(0.1, 3, layers, weights).Predict(set.Input))
+		}
+		return sum
+	}
+	for l := range weights {
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				shifted := copyWeights(weights)
+				shifted[l][j][i] = weight + 1e-6
+				upper := loss(shifted)
+				shifted[l][j][i] = weight - 1e-6
+				lower := loss(shifted)
+				numeric := (upper - lower) / 2e-6
+				analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+				if math.Abs(numeric - analytic) > 1e-6 {
+					t.Log("Failure - Embedding gradients disagree with finite differences")
+					t.Log(l, j, i, analytic, numeric)
+					t.Fail()
+					return
+				}
+			}
+		}
+	}
+	t.Log("Success - Embedding updates only the rows seen in the batch")
+}
+
+func Test_Network_Embedding_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 1000
+	layers := embeddingLayers()
+	weights := RandomWeights(3, layers, 11)
+	network, _ := NewNetwork(0.5, 3, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 3, layers, weights)
+
+	go func() {
+		var divergence float64
+		for epoch := 0; epoch < 10; epoch++ {
+			expected := matrix.LearnBatch(embeddingSets)
+			for s, output := range network.LearnBatch(embeddingSets) {
+				divergence = math.Max(divergence, math.Abs(output[0] - expected[s][0]))
+			}
+		}
+		after := network.Weights()
+		for l := range after {
+			for j := range after[l] {
+				for i := range after[l][j] {
+					divergence = math.Max(divergence, math.Abs(after[l][j][i] - matrix.Weights[l][j][i]))
+				}
+			}
+		}
+		var buffer bytes.Buffer
+		network.Save(&buffer)
+		loaded, err := Load(&buffer)
+		if err != nil {
+			resultchan <- math.Inf(1)
+			return
+		}
+		defer loaded.Close()
+		for _, set := range embeddingSets {
+			divergence = math.Max(divergence, math.Abs(loaded.Predict(set.Input)[0] - matrix.Predict(set.Input)[0]))
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Embedding network timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Embedding network diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Embedding network matches the matrix engine and survives a save and load")
+		return
+	}
+}
@@ -0,0 +1,134 @@
+package ann
+
+import (
+	"math"
+	"sort"
+)
+
+type ClassMetrics struct {
+	Precision float64
+	Recall float64
+	F1 float64
+	Support int
+}
+
+type Evaluation struct {
+	Samples int
+	Loss float64
+	Accuracy float64
+	Classes []ClassMetrics
+	Confusion [][]int
+	AUC float64
+	MSE float64
+	MAE float64
+	R2 float64
+}
+
+func Evaluate (network Predictor, regimen Regimen) Evaluation {
+	expects := make([][]float64, len(regimen.TrainingSets))
+	outputs := make([][]float64, len(regimen.TrainingSets))
+	for s, set := range regimen.TrainingSets {
+		expects[s] = set.Expect
+		outputs[s] = network.Predict(set.Input)
+	}
+	return Score(expects, outputs)
+}
+
+func Score (expects [][]float64, outputs [][]float64) Evaluation {
+	evaluation := Evaluation {Samples: len(expects), AUC: math.NaN()}
+	if len(expects) == 0 {return evaluation}
+	width := len(expects[0])
+	classes := width
+	if width == 1 {classes = 2}
+	evaluation.Confusion = make([][]int, classes)
+	for c := range evaluation.Confusion {
+		evaluation.Confusion[c] = make([]int, classes)
+	}
+	means := make([]float64, width)
+	for _, expect := range expects {
+		for j := range expect {
+			means[j] = means[j] + expect[j] / float64(len(expects))
+		}
+	}
+	var residual, total float64
+	for s := range expects {
+		evaluation.Loss = evaluation.Loss + SquaredError(expects[s], outputs[s]) / float64(len(expects))
+		for j := range expects[s] {
+			deviation := expects[s][j] - outputs[s][j]
+			evaluation.MSE = evaluation.MSE + deviation * deviation / float64(len(expects) * width)
+			evaluation.MAE = evaluation.MAE + math.Abs(deviation) / float64(len(expects) * width)
+			residual = residual + deviation * deviation
+			total = total + (expects[s][j] - means[j]) * (expects[s][j] - means[j])
+		}
+		actual := classify(expects[s], classes); predicted := classify(outputs[s], classes)
+		evaluation.Confusion[actual][predicted]++
+		if actual == predicted {evaluation.Accuracy = evaluation.Accuracy + 1 / float64(len(expects))}
+	}
+	if total > 0 {evaluation.R2 = 1 - residual / total}
+	evaluation.Classes = make([]ClassMetrics, classes)
+	for c := range evaluation.Classes {
+		var predicted, actual int
+		for k := 0; k < classes; k++ {
+			predicted = predicted + evaluation.Confusion[k][c]
+			actual = actual + evaluation.Confusion[c][k]
+		}
+		metrics := ClassMetrics {Support: actual}
+		if predicted > 0 {metrics.Precision = float64(evaluation.Confusion[c][c]) / float64(predicted)}
+		if actual > 0 {metrics.Recall = float64(evaluation.Confusion[c][c]) / float64(actual)}
+		if metrics.Precision + metrics.Recall > 0 {metrics.F1 = 2 * metrics.Precision * metrics.Recall / (metrics.Precision + metrics.Recall)}
+		evaluation.Classes[c] = metrics
+	}
+	if width == 1 {evaluation.AUC = auc(expects, outputs)}
+	return evaluation
+}
+
+func classify (values []float64, classes int) int {
+	label := TrainingSet {Expect: values}.Label()
+	if label < 0 {return 0}
+	if label >= classes {return classes - 1}
+	return label
+}
+
+func auc (expects [][]float64, outputs [][]float64) float64 {
+	order := make([]int, len(outputs))
+	for s := range order {
+		order[s] = s
+	}
+	sort.Slice(order, func (a, b int) bool {return outputs[order[a]][0] < outputs[order[b]][0]})
+	var positives, negatives, ranksum float64
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && outputs[order[end]][0] == outputs[order[start]][0] {
+			end++
+		}
+		rank := float64(start + end + 1) / 2
+		for _, s := range order[start:end] {
+			if classify(expects[s], 2) == 1 {
+				positives++
+				ranksum = ranksum + rank
+			} else {
+				negatives++
+			}
+		}
+		start = end
+	}
+	if positives == 0 || negatives == 0 {return math.NaN()}
+	return (ranksum - positives * (positives + 1) / 2) / (positives * negatives)
+}
+
+func (evaluation Evaluation) Metrics () map[string]float64 {
+	metrics := map[string]float64 {
+		"loss": evaluation.Loss,
+		"accuracy": evaluation.Accuracy,
+		"mse": evaluation.MSE,
+		"mae": evaluation.MAE,
+		"r2": evaluation.R2,
+	}
+	for _, class := range evaluation.Classes {
+		metrics["precision"] = metrics["precision"] + class.Precision / float64(len(evaluation.Classes))
+		metrics["recall"] = metrics["recall"] + class.Recall / float64(len(evaluation.Classes))
+		metrics["f1"] = metrics["f1"] + class.F1 / float64(len(evaluation.Classes))
+	}
+	if !math.IsNaN(evaluation.AUC) {metrics["auc"] = evaluation.AUC}
+	return metrics
+}
@@ -0,0 +1,92 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Score_Binary_Confusion (t *testing.T) {
+	expects := [][]float64 {{1}, {1}, {0}, {0}}
+	outputs := [][]float64 {{0.9}, {0.4}, {0.2}, {0.6}}
+	evaluation := Score(expects, outputs)
+	if evaluation.Confusion[1][1] != 1 || evaluation.Confusion[1][0] != 1 || evaluation.Confusion[0][1] != 1 || evaluation.Accuracy != 0.5 {
+		t.Log("Failure - Binary confusion matrix inaccurate")
+		t.Log(evaluation.Confusion)
+		t.Fail()
+		return
+	}
+	if evaluation.Classes[1].Precision != 0.5 || evaluation.Classes[1].Recall != 0.5 || evaluation.Classes[1].F1 != 0.5 {
+		t.Log("Failure - Binary class metrics inaccurate")
+		t.Log(evaluation.Classes)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Binary confusion matrix is accurate")
+}
+
+func Test_Score_Binary_AUC (t *testing.T) {
+	expects := [][]float64 {{1}, {1}, {0}, {0}}
+	outputs := [][]float64 {{0.9}, {0.4}, {0.2}, {0.6}}
+	if evaluation := Score(expects, outputs); evaluation.AUC != 0.75 {
+		t.Log("Failure - Binary ROC-AUC inaccurate")
+		t.Log(evaluation.AUC)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Binary ROC-AUC is accurate")
+}
+
+func Test_Score_Multiclass_Argmax (t *testing.T) {
+	expects := [][]float64 {{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	outputs := [][]float64 {{0.7, 0.2, 0.1}, {0.1, 0.3, 0.6}, {0.2, 0.1, 0.8}}
+	evaluation := Score(expects, outputs)
+	if len(evaluation.Confusion) != 3 || evaluation.Confusion[1][2] != 1 || evaluation.Classes[2].Precision != 0.5 || !math.IsNaN(evaluation.AUC) {
+		t.Log("Failure - Multiclass metrics inaccurate")
+		t.Log(evaluation.Confusion)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Multiclass metrics are accurate")
+}
+
+func Test_Score_Regression_Errors (t *testing.T) {
+	expects := [][]float64 {{1}, {2}, {3}}
+	outputs := [][]float64 {{1.5}, {2}, {2.5}}
+	evaluation := Score(expects, outputs)
+	if math.Abs(evaluation.MSE - 1.0 / 6) > 1e-12 || math.Abs(evaluation.MAE - 1.0 / 3) > 1e-12 || math.Abs(evaluation.R2 - 0.75) > 1e-12 {
+		t.Log("Failure - Regression metrics inaccurate")
+		t.Log(evaluation.MSE, evaluation.MAE, evaluation.R2)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Regression metrics are accurate")
+}
+
+func Test_Evaluate_Workflow (t *testing.T) {
+	resultchan := make(chan Evaluation)
+	var timeout time.Duration = 50
+	layers := []Layer {{Neurons: 1, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0.5, 1, layers, RandomWeights(1, layers, 1))
+	before := network.Weights()
+
+	go func() {
+		resultchan <- Evaluate(network, labelled(6, 2))
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Evaluate timed out")
+		t.Fail()
+		return
+	case evaluation := <- resultchan:
+		after := network.Weights()
+		if evaluation.Samples != 6 || before[0][0][0] != after[0][0][0] || before[0][0][1] != after[0][0][1] {
+			t.Log("Failure - Evaluate is not in inference mode")
+			t.Fail()
+			return
+		}
+		t.Log("Success - Evaluate workflow is clear")
+		return
+	}
+}
@@ -0,0 +1,349 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const devevolution bool = false
+
+type Fitness func (predictor Predictor) float64
+
+type Evolution struct {
+	Method string
+	Population int
+	Sigma float64
+	LearnRate float64
+	Survival float64
+	Mutation float64
+	Seed int64
+}
+
+type evolvable interface {
+	Predictor
+	SetWeights (weights [][][]float64)
+}
+
+type strategy interface {
+	ask () [][]float64
+	tell (candidates [][]float64, fitnesses []float64)
+	center () []float64
+}
+
+type genetic struct {
+	evolution Evolution
+	random *rand.Rand
+	population [][]float64
+}
+
+type estimator struct {
+	evolution Evolution
+	random *rand.Rand
+	theta []float64
+	noise [][]float64
+}
+
+type separable struct {
+	evolution Evolution
+	random *rand.Rand
+	mean []float64
+	variances []float64
+	sigmapath []float64
+	covariancepath []float64
+	sigma float64
+	weights []float64
+	mueff, cs, ds, cc, c1, cmu, chin float64
+	generation int
+	steps [][]float64
+}
+
+func LossFitness (regimen Regimen) Fitness {
+	return func (predictor Predictor) float64 {return -Evaluate(predictor, regimen).Loss}
+}
+
+func flatten (layers []Layer, weights [][][]float64) []float64 {
+	var vector []float64
+	for l := range weights {
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				if layers[l].Mask != nil && !layers[l].Mask[j][i] {continue}
+				vector = append(vector, weight)
+			}
+		}
+	}
+	return vector
+}
+
+func unflatten (vector []float64, layers []Layer, shape [][][]float64) [][][]float64 {
+	weights := copyWeights(shape)
+	k := 0
+	for l := range weights {
+		for j := range weights[l] {
+			for i := range weights[l][j] {
+				if layers[l].Mask != nil && !layers[l].Mask[j][i] {continue}
+				weights[l][j][i] = vector[k]
+				k++
+			}
+		}
+	}
+	return weights
+}
+
+func ranking (fitnesses []float64) []int {
+	order := make([]int, len(fitnesses))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func (a int, b int) bool {return fitnesses[order[a]] > fitnesses[order[b]]})
+	return order
+}
+
+func (evolution Evolution) defaults (dimensions int) Evolution {
+	if evolution.Sigma <= 0 {evolution.Sigma = 0.1}
+	if evolution.LearnRate <= 0 {evolution.LearnRate = 0.05}
+	if evolution.Survival <= 0 {evolution.Survival = 0.2}
+	if evolution.Mutation <= 0 {evolution.Mutation = 0.1}
+	if evolution.Population <= 0 {
+		evolution.Population = 50
+		if evolution.Method == "sep-cmaes" {evolution.Population = 4 + int(3 * math.Log(float64(dimensions)))}
+	}
+	if evolution.Method == "es" && evolution.Population % 2 == 1 {evolution.Population++}
+	return evolution
+}
+
+func (evolution Evolution) strategy (center []float64) (strategy, error) {
+	random := rand.New(rand.NewSource(evolution.Seed))
+	switch evolution.Method {
+	case "ga":
+		search := &genetic {evolution: evolution, random: random, population: [][]float64 {center}}
+		for len(search.population) < evolution.Population {
+			member := make([]float64, len(center))
+			for i := range member {
+				member[i] = center[i] + random.NormFloat64() * evolution.Sigma
+			}
+			search.population = append(search.population, member)
+		}
+		return search, nil
+	case "es":
+		return &estimator {evolution: evolution, random: random, theta: append([]float64 {}, center...)}, nil
+	case "sep-cmaes":
+		return newSeparable(evolution, random, center), nil
+	}
+	return nil, fmt.Errorf("ann: unknown evolution method %q", evolution.Method)
+}
+
+func (search *genetic) ask () [][]float64 {
+	return search.population
+}
+
+func (search *genetic) tell (candidates [][]float64, fitnesses []float64) {
+	order := ranking(fitnesses)
+	survivors := int(math.Max(1, math.Ceil(search.evolution.Survival * float64(len(candidates)))))
+	next := make([][]float64, 0, len(candidates))
+	for _, k := range order[:survivors] {
+		next = append(next, candidates[k])
+	}
+	for len(next) < len(candidates) {
+		first, second := candidates[order[search.random.Intn(survivors)]], candidates[order[search.random.Intn(survivors)]]
+		child := make([]float64, len(first))
+		for i := range child {
+			child[i] = first[i]
+			if search.random.Float64() < 0.5 {child[i] = second[i]}
+			if search.random.Float64() < search.evolution.Mutation {child[i] = child[i] + search.random.NormFloat64() * search.evolution.Sigma}
+		}
+		next = append(next, child)
+	}
+	search.population = next
+}
+
+func (search *genetic) center () []float64 {
+	return search.population[0]
+}
+
+func (search *estimator) ask () [][]float64 {
+	search.noise = make([][]float64, search.evolution.Population / 2)
+	candidates := make([][]float64, 0, search.evolution.Population)
+	for p := range search.noise {
+		search.noise[p] = make([]float64, len(search.theta))
+		positive, negative := make([]float64, len(search.theta)), make([]float64, len(search.theta))
+		for i := range search.theta {
+			search.noise[p][i] = search.random.NormFloat64()
+			positive[i] = search.theta[i] + search.evolution.Sigma * search.noise[p][i]
+			negative[i] = search.theta[i] - search.evolution.Sigma * search.noise[p][i]
+		}
+		candidates = append(candidates, positive, negative)
+	}
+	return candidates
+}
+
+func (search *estimator) tell (candidates [][]float64, fitnesses []float64) {
+	utilities := make([]float64, len(candidates))
+	for rank, k := range ranking(fitnesses) {
+		utilities[k] = 0.5 - float64(rank) / float64(len(candidates) - 1)
+	}
+	scale := search.evolution.LearnRate / (float64(len(candidates)) * search.evolution.Sigma)
+	for p, noise := range search.noise {
+		for i, value := range noise {
+			search.theta[i] = search.theta[i] + scale * (utilities[2 * p] - utilities[2 * p + 1]) * value
+		}
+	}
+}
+
+func (search *estimator) center () []float64 {
+	return search.theta
+}
+
+func newSeparable (evolution Evolution, random *rand.Rand, center []float64) *separable {
+	n := float64(len(center))
+	mu := evolution.Population / 2
+	search := &separable {evolution: evolution, random: random, mean: append([]float64 {}, center...), sigma: evolution.Sigma, weights: make([]float64, mu)}
+	var sum, squares float64
+	for i := range search.weights {
+		search.weights[i] = math.Log(float64(mu) + 0.5) - math.Log(float64(i + 1))
+		sum = sum + search.weights[i]
+	}
+	for i := range search.weights {
+		search.weights[i] = search.weights[i] / sum
+		squares = squares + search.weights[i] * search.weights[i]
+	}
+	search.mueff = 1 / squares
+	search.cs = (search.mueff + 2) / (n + search.mueff + 5)
+	search.ds = 1 + 2 * math.Max(0, math.Sqrt((search.mueff - 1) / (n + 1)) - 1) + search.cs
+	search.cc = (4 + search.mueff / n) / (n + 4 + 2 * search.mueff / n)
+	search.c1 = 2 / ((n + 1.3) * (n + 1.3) + search.mueff) * (n + 2) / 3
+	search.cmu = math.Min(1 - search.c1, 2 * (search.mueff - 2 + 1 / search.mueff) / ((n + 2) * (n + 2) + search.mueff) * (n + 2) / 3)
+	search.chin = math.Sqrt(n) * (1 - 1 / (4 * n) + 1 / (21 * n * n))
+	search.variances = make([]float64, len(center))
+	search.sigmapath, search.covariancepath = make([]float64, len(center)), make([]float64, len(center))
+	for i := range search.variances {
+		search.variances[i] = 1
+	}
+	return search
+}
+
+func (search *separable) ask () [][]float64 {
+	search.steps = make([][]float64, search.evolution.Population)
+	candidates := make([][]float64, search.evolution.Population)
+	for k := range candidates {
+		search.steps[k], candidates[k] = make([]float64, len(search.mean)), make([]float64, len(search.mean))
+		for i := range search.mean {
+			search.steps[k][i] = math.Sqrt(search.variances[i]) * search.random.NormFloat64()
+			candidates[k][i] = search.mean[i] + search.sigma * search.steps[k][i]
+		}
+	}
+	return candidates
+}
+
+func (search *separable) tell (candidates [][]float64, fitnesses []float64) {
+	order := ranking(fitnesses)
+	step := make([]float64, len(search.mean))
+	for r, weight := range search.weights {
+		for i, value := range search.steps[order[r]] {
+			step[i] = step[i] + weight * value
+		}
+	}
+	var norm float64
+	for i := range search.mean {
+		search.mean[i] = search.mean[i] + search.sigma * step[i]
+		search.sigmapath[i] = (1 - search.cs) * search.sigmapath[i] + math.Sqrt(search.cs * (2 - search.cs) * search.mueff) * step[i] / math.Sqrt(search.variances[i])
+		norm = norm + search.sigmapath[i] * search.sigmapath[i]
+	}
+	norm = math.Sqrt(norm)
+	search.generation++
+	hsig := 0.0
+	if norm / math.Sqrt(1 - math.Pow(1 - search.cs, float64(2 * search.generation))) < (1.4 + 2 / (float64(len(search.mean)) + 1)) * search.chin {hsig = 1}
+	for i := range search.mean {
+		search.covariancepath[i] = (1 - search.cc) * search.covariancepath[i] + hsig * math.Sqrt(search.cc * (2 - search.cc) * search.mueff) * step[i]
+		var rankmu float64
+		for r, weight := range search.weights {
+			rankmu = rankmu + weight * search.steps[order[r]][i] * search.steps[order[r]][i]
+		}
+		rankone := search.covariancepath[i] * search.covariancepath[i] + (1 - hsig) * search.cc * (2 - search.cc) * search.variances[i]
+		search.variances[i] = (1 - search.c1 - search.cmu) * search.variances[i] + search.c1 * rankone + search.cmu * rankmu
+	}
+	search.sigma = search.sigma * math.Exp(search.cs / search.ds * (norm / search.chin - 1))
+}
+
+func (search *separable) center () []float64 {
+	return search.mean
+}
+
+func scatter (members []evolvable, candidates [][]float64, layers []Layer, shape [][][]float64, fitness Fitness) []float64 {
+	type scored struct {
+		candidate int
+		fitness float64
+	}
+	results := make(chan scored)
+	idle := make(chan evolvable, len(members))
+	for _, member := range members {
+		idle <- member
+	}
+	for k, candidate := range candidates {
+		go func (k int, candidate []float64) {
+			member := <- idle
+			member.SetWeights(unflatten(candidate, layers, shape))
+			results <- scored {candidate: k, fitness: fitness(member)}
+			idle <- member
+		}(k, candidate)
+	}
+	fitnesses := make([]float64, len(candidates))
+	for range candidates {
+		result := <- results
+		fitnesses[result.candidate] = result.fitness
+	}
+	return fitnesses
+}
+
+func evolve (search strategy, members []evolvable, layers []Layer, shape [][][]float64, fitness Fitness, generations int) ([][][]float64, []float64) {
+	best := flatten(layers, shape)
+	record := scatter(members, [][]float64 {best}, layers, shape, fitness)[0]
+	var history []float64
+	for generation := 0; generation < generations; generation++ {
+		candidates := search.ask()
+		fitnesses := scatter(members, candidates, layers, shape, fitness)
+		for k, value := range fitnesses {
+			if value > record {best, record = append([]float64 {}, candidates[k]...), value}
+		}
+		search.tell(candidates, fitnesses)
+		history = append(history, record)
+		if devevolution {fmt.Printf("\n%v: Generation %d best fitness [%f]...\n", time.Now(), generation, record)}
+	}
+	if value := scatter(members, [][]float64 {search.center()}, layers, shape, fitness)[0]; value > record {best, record = append([]float64 {}, search.center()...), value}
+	return unflatten(best, layers, shape), history
+}
+
+func (network *Network) Evolve (evolution Evolution, fitness Fitness, generations int) ([]float64, error) {
+	model := network.Model()
+	center := flatten(model.Layers, model.Weights)
+	evolution = evolution.defaults(len(center))
+	search, err := evolution.strategy(center)
+	if err != nil {return nil, err}
+	members := make([]evolvable, evolution.Population)
+	for m := range members {
+		member, err := model.Network()
+		if err != nil {return nil, err}
+		defer member.Close()
+		members[m] = member
+	}
+	weights, history := evolve(search, members, model.Layers, model.Weights, fitness, generations)
+	network.SetWeights(weights)
+	return history, nil
+}
+
+func (matrix *Matrix) Evolve (evolution Evolution, fitness Fitness, generations int) ([]float64, error) {
+	model := matrix.Model()
+	center := flatten(model.Layers, model.Weights)
+	evolution = evolution.defaults(len(center))
+	search, err := evolution.strategy(center)
+	if err != nil {return nil, err}
+	members := make([]evolvable, evolution.Population)
+	for m := range members {
+		members[m] = model.Matrix()
+	}
+	weights, history := evolve(search, members, model.Layers, model.Weights, fitness, generations)
+	matrix.SetWeights(weights)
+	return history, nil
+}
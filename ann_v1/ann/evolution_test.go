@@ -0,0 +1,86 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Matrix_Evolution_XOR (t *testing.T) {
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 11)
+	for _, method := range []string {"ga", "es", "sep-cmaes"} {
+		matrix := NewMatrix(0.5, 2, layers, weights)
+		history, err := matrix.Evolve(Evolution {Method: method, Sigma: 0.5, LearnRate: 0.5, Seed: 3}, LossFitness(xorRegimen), 200)
+		evaluation := Evaluate(matrix, xorRegimen)
+		if err != nil || len(history) != 200 || evaluation.Accuracy != 1 || evaluation.Loss > 0.02 || -evaluation.Loss < history[len(history) - 1] {
+			t.Log("Failure - Evolution did not solve XOR")
+			t.Log(method, err, evaluation.Loss, evaluation.Accuracy)
+			t.Fail()
+			return
+		}
+		for g := 1; g < len(history); g++ {
+			if history[g] < history[g - 1] {
+				t.Log("Failure - Evolution lost its best fitness")
+				t.Log(method, g, history[g - 1], history[g])
+				t.Fail()
+				return
+			}
+		}
+	}
+	if _, err := NewMatrix(0.5, 2, layers, weights).Evolve(Evolution {Method: "annealing"}, LossFitness(xorRegimen), 1); err == nil {
+		t.Log("Failure - Evolution accepted an unknown method")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Genetic algorithm and evolution strategies solve XOR")
+}
+
+func Test_Network_Evolution_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 5000
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 11)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	evolution := Evolution {Method: "ga", Population: 20, Sigma: 1, Seed: 7}
+	accuracy := func (predictor Predictor) float64 {return Evaluate(predictor, xorRegimen).Accuracy}
+	var histories [2][]float64
+
+	go func() {
+		var divergence float64
+		histories[0], _ = network.Evolve(evolution, accuracy, 40)
+		histories[1], _ = matrix.Evolve(evolution, accuracy, 40)
+		for l, layer := range network.Weights() {
+			for j := range layer {
+				for i, weight := range layer[j] {
+					divergence = math.Max(divergence, math.Abs(weight - matrix.Weights[l][j][i]))
+				}
+			}
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Evolving network timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Evolved network diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		if histories[0][len(histories[0]) - 1] != 1 || Evaluate(network, xorRegimen).Accuracy != 1 {
+			t.Log("Failure - Evolution did not maximize a non-differentiable fitness")
+			t.Log(histories[0])
+			t.Fail()
+			return
+		}
+		t.Log("Success - Evolved network matches the matrix engine")
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package ann
+
+import (
+	"math"
+)
+
+type GradientCheck struct {
+	Layer int
+	Neuron int
+	Synapse int
+	Analytic float64
+	Numeric float64
+	RelativeError float64
+}
+
+func CheckGradients (network *Network, set TrainingSet, epsilon float64) ([]GradientCheck, error) {
+	var checks []GradientCheck
+	weights := network.Weights()
+	model := network.Model()
+	model.LearnRate = 1
+	twin, err := model.Network()
+	if err != nil {return nil, err}
+	twin.Learn(set)
+	gradients := twin.Weights()
+	twin.Close()
+	loss := func () float64 {return SquaredError(set.Expect, network.Predict(set.Input))}
+	for l := range weights {
+		if network.tables[l].Read != nil {continue}
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				if rules := plastic(network.Layers, l); rules != nil && rules[j][i].Delta != nil {continue}
+				probe := network.probes[l][j][i]
+				probe.Write <- weight + epsilon
+				upper := loss()
+				probe.Write <- weight - epsilon
+				lower := loss()
+				probe.Write <- weight
+				check := GradientCheck {
+					Layer: l,
+					Neuron: j,
+					Synapse: i,
+					Analytic: weight - gradients[l][j][i],
+					Numeric: (upper - lower) / (2 * epsilon),
+				}
+				if scale := math.Abs(check.Analytic) + math.Abs(check.Numeric); scale > 0 {
+					check.RelativeError = math.Abs(check.Analytic - check.Numeric) / scale
+				}
+				checks = append(checks, check)
+			}
+		}
+	}
+	return checks, nil
+}
+
+func WorstGradient (checks []GradientCheck) GradientCheck {
+	var worst GradientCheck
+	for _, check := range checks {
+		if check.RelativeError >= worst.RelativeError {worst = check}
+	}
+	return worst
+}
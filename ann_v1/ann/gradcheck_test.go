@@ -0,0 +1,107 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_CheckGradients_Multilayer (t *testing.T) {
+	resultchan := make(chan []GradientCheck)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0.1, 2, layers, RandomWeights(2, layers, 11))
+	set := TrainingSet {Input: []float64 {0.3, -0.8}, Expect: []float64 {1, 0}}
+
+	go func() {
+		checks, _ := CheckGradients(network, set, 1e-5)
+		resultchan <- checks
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Gradient check timed out")
+		t.Fail()
+		return
+	case checks := <- resultchan:
+		if len(checks) != 3 * 3 + 2 * 4 + 2 * 3 {
+			t.Log("Failure - Gradient check skipped synapses")
+			t.Fail()
+			return
+		}
+		if worst := WorstGradient(checks); worst.RelativeError > 1e-6 {
+			t.Log("Failure - Channel pipeline gradients disagree with finite differences")
+			t.Log(worst)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Channel pipeline gradients agree with finite differences")
+		return
+	}
+}
+
+func Test_CheckGradients_ZeroLearnRate (t *testing.T) {
+	resultchan := make(chan []GradientCheck)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0, 2, layers, RandomWeights(2, layers, 3))
+	defer network.Close()
+	set := TrainingSet {Input: []float64 {0.6, 0.2}, Expect: []float64 {0, 1}}
+
+	go func() {
+		checks, _ := CheckGradients(network, set, 1e-5)
+		resultchan <- checks
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Gradient check timed out")
+		t.Fail()
+		return
+	case checks := <- resultchan:
+		for _, check := range checks {
+			if math.IsNaN(check.Analytic) || math.IsInf(check.Analytic, 0) || check.RelativeError > 1e-6 {
+				t.Log("Failure - Gradients depend on the learning rate")
+				t.Log(check)
+				t.Fail()
+				return
+			}
+		}
+		t.Log("Success - Gradients are exact without a learning rate")
+		return
+	}
+}
+
+func Test_CheckGradients_RestoresWeights (t *testing.T) {
+	resultchan := make(chan struct{})
+	var timeout time.Duration = 100
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0.5, 1, layers, RandomWeights(1, layers, 5))
+
+	go func() {
+		before := network.Weights()
+		CheckGradients(network, TrainingSet {Input: []float64 {1}, Expect: []float64 {0}}, 1e-4)
+		after := network.Weights()
+		for l := range before {
+			for j := range before[l] {
+				for i := range before[l][j] {
+					if before[l][j][i] != after[l][j][i] {
+						t.Log("Failure - Gradient check left weights perturbed")
+						return
+					}
+				}
+			}
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Gradient check restore timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Gradient check restores weights")
+		return
+	}
+}
@@ -0,0 +1,218 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+const devhopfield bool = false
+
+var sign = Activation {Name: "sign", Function: func (x float64) float64 {if x < 0 {return -1}; return 1}, Derivative: func (x float64) float64 {return 0}}
+
+type Hopfield struct {
+	Units int
+	neurons []SignalPeripherals
+	probes [][]Probe
+	biases []float64
+	faults chan Fault
+	cancelchan chan struct{}
+}
+
+func HebbianWeights (patterns [][]float64) [][]float64 {
+	units := 0
+	if len(patterns) > 0 {units = len(patterns[0])}
+	weights := make([][]float64, units)
+	for i := range weights {
+		weights[i] = make([]float64, units + 1)
+		for j := range patterns[0] {
+			if i == j {continue}
+			for _, pattern := range patterns {
+				weights[i][j] = weights[i][j] + pattern[i] * pattern[j] / float64(units)
+			}
+		}
+	}
+	return weights
+}
+
+func SymmetricSynapse (weight float64, slots [2]int, peripherals [2]SignalPeripherals, probe Probe, cancelchan chan struct{}) {
+	var states [2]float64
+	for {
+		select {
+		case state := <- peripherals[0].Input:
+			states[0] = state.Values[0]
+		case state := <- peripherals[1].Input:
+			states[1] = state.Values[0]
+		case query := <- peripherals[0].Upfeed:
+			if !PushSignalOrCancel(Signal {Step: query.Step, Source: slots[0], Values: []float64 {weight * states[1]}}, peripherals[0].Output, cancelchan) {return}
+		case query := <- peripherals[1].Upfeed:
+			if !PushSignalOrCancel(Signal {Step: query.Step, Source: slots[1], Values: []float64 {weight * states[0]}}, peripherals[1].Output, cancelchan) {return}
+		case probe.Read <- weight:
+		case weight = <- probe.Write:
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func HopfieldNucleus (unit int, bias float64, peripherals SignalPeripherals, queries []chan Signal, states []chan Signal, cancelchan chan struct{}) {
+	var state float64
+	broadcast := func (step int) bool {
+		for _, lane := range states {
+			if !PushSignalOrCancel(Signal {Step: step, Source: unit, Values: []float64 {state}}, lane, cancelchan) {return false}
+		}
+		return true
+	}
+	for {
+		select {
+		case command := <- peripherals.Input:
+			previous := state
+			if len(command.Values) > 0 {
+				state = command.Values[0]
+			} else {
+				field := bias
+				for _, lane := range queries {
+					if !PushSignalOrCancel(Signal {Step: command.Step, Source: unit}, lane, cancelchan) {return}
+				}
+				if len(queries) > 0 {
+					ok, sum := PullSignalOrCancel(peripherals.Upfeed, cancelchan)
+					if !ok {return}
+					field = sum.Values[0] + bias
+				}
+				if field > 0 {state = 1}
+				if field < 0 {state = -1}
+			}
+			if state != previous && !broadcast(command.Step) {return}
+			if devhopfield && state != previous {fmt.Printf("\n%v: Unit %d flipped to [%f]...\n", time.Now(), unit, state)}
+			if !PushSignalOrCancel(Signal {Step: command.Step, Source: unit, Values: []float64 {state}}, peripherals.Output, cancelchan) {return}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func CheckHopfield (weights [][]float64) error {
+	units := len(weights)
+	for i := range weights {
+		if len(weights[i]) != units + 1 {return fmt.Errorf("ann: Hopfield unit %d has %d weights but needs %d", i, len(weights[i]), units + 1)}
+	}
+	for i := range weights {
+		if weights[i][i] != 0 {return fmt.Errorf("ann: Hopfield unit %d connects to itself", i)}
+		for j := i + 1; j < units; j++ {
+			if weights[i][j] != weights[j][i] {return fmt.Errorf("ann: Hopfield weights between units %d and %d are not symmetric", i, j)}
+		}
+	}
+	return nil
+}
+
+func NewHopfield (weights [][]float64) (*Hopfield, error) {
+	if err := CheckHopfield(weights); err != nil {return nil, err}
+	units := len(weights)
+	hopfield := &Hopfield {Units: units, faults: make(chan Fault, 64), cancelchan: make(chan struct{})}
+	queries := make([][]chan Signal, units)
+	states := make([][]chan Signal, units)
+	replies := make([]chan Signal, units)
+	hopfield.probes = make([][]Probe, units)
+	for i := range weights {
+		replies[i] = make(chan Signal)
+		hopfield.probes[i] = make([]Probe, units)
+		hopfield.biases = append(hopfield.biases, weights[i][units])
+	}
+	for i := range weights {
+		for j := i + 1; j < units; j++ {
+			var peripherals [2]SignalPeripherals
+			var slots [2]int
+			for e, end := range []int {i, j} {
+				slots[e] = len(queries[end])
+				peripherals[e] = SignalPeripherals {Input: make(chan Signal), Output: replies[end], Upfeed: make(chan Signal)}
+				states[end] = append(states[end], peripherals[e].Input)
+				queries[end] = append(queries[end], peripherals[e].Upfeed)
+			}
+			probe := Probe {Read: make(chan float64), Write: make(chan float64)}
+			hopfield.probes[i][j], hopfield.probes[j][i] = probe, probe
+			go SymmetricSynapse (weights[i][j], slots, peripherals, probe, hopfield.cancelchan)
+		}
+	}
+	for i := range weights {
+		internals := SignalPeripherals {Input: make(chan Signal), Output: make(chan Signal), Upfeed: make(chan Signal)}
+		hopfield.neurons = append(hopfield.neurons, internals)
+		if len(queries[i]) > 0 {go TaggedDendrite (len(queries[i]), replies[i], internals.Upfeed, hopfield.faults, hopfield.cancelchan)}
+		go HopfieldNucleus (i, hopfield.biases[i], internals, queries[i], states[i], hopfield.cancelchan)
+	}
+	return hopfield, nil
+}
+
+func (hopfield *Hopfield) command (unit int, signal Signal) float64 {
+	hopfield.neurons[unit].Input <- signal
+	return (<- hopfield.neurons[unit].Output).Values[0]
+}
+
+func (hopfield *Hopfield) Recall (pattern []float64, sweeps int, seed int64) []float64 {
+	random := rand.New(rand.NewSource(seed))
+	state := make([]float64, hopfield.Units)
+	for i, value := range pattern {
+		state[i] = hopfield.command(i, Signal {Values: []float64 {value}})
+	}
+	for sweep := 0; sweep < sweeps; sweep++ {
+		changed := false
+		for _, unit := range random.Perm(hopfield.Units) {
+			next := hopfield.command(unit, Signal {Step: sweep + 1})
+			changed = changed || next != state[unit]
+			state[unit] = next
+		}
+		if !changed {break}
+	}
+	return state
+}
+
+func (hopfield *Hopfield) Weights () [][]float64 {
+	weights := make([][]float64, hopfield.Units)
+	for i := range weights {
+		weights[i] = make([]float64, hopfield.Units + 1)
+		for j, probe := range hopfield.probes[i] {
+			if j != i {weights[i][j] = <- probe.Read}
+		}
+		weights[i][hopfield.Units] = hopfield.biases[i]
+	}
+	return weights
+}
+
+func Energy (weights [][]float64, state []float64) float64 {
+	var energy float64
+	for i := range state {
+		for j := range state {
+			energy = energy - 0.5 * weights[i][j] * state[i] * state[j]
+		}
+		energy = energy - weights[i][len(state)] * state[i]
+	}
+	return energy
+}
+
+func (hopfield *Hopfield) Energy (state []float64) float64 {
+	return Energy(hopfield.Weights(), state)
+}
+
+func (hopfield *Hopfield) Model () Model {
+	return Model {Inputs: hopfield.Units, Layers: []Layer {{Neurons: hopfield.Units, Activation: sign}}, Weights: [][][]float64 {hopfield.Weights()}}
+}
+
+func (hopfield *Hopfield) Save (writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(hopfield.Model())
+}
+
+func LoadHopfield (reader io.Reader) (*Hopfield, error) {
+	model, err := LoadModel(reader)
+	if err != nil {return nil, err}
+	if len(model.Layers) != 1 || model.Layers[0].Neurons != model.Inputs {return nil, fmt.Errorf("ann: model is not a Hopfield network")}
+	return NewHopfield(model.Weights[0])
+}
+
+func (hopfield *Hopfield) Faults () chan Fault {
+	return hopfield.faults
+}
+
+func (hopfield *Hopfield) Close () {
+	close(hopfield.cancelchan)
+}
@@ -0,0 +1,152 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Hopfield_Recall (t *testing.T) {
+	resultchan := make(chan [][]float64)
+	var timeout time.Duration = 2000
+	patterns := [][]float64 {
+		{1, 1, 1, 1, -1, -1, -1, -1, 1, -1, 1, -1},
+		{1, -1, 1, -1, 1, -1, 1, -1, -1, -1, 1, 1},
+	}
+	weights := HebbianWeights(patterns)
+	hopfield, _ := NewHopfield(weights)
+	defer hopfield.Close()
+	corrupted := [][]float64 {append([]float64 {}, patterns[0]...), append([]float64 {}, patterns[1]...)}
+	corrupted[0][0], corrupted[1][3] = -1, 1
+	var energies [][2]float64
+
+	go func() {
+		var recalled [][]float64
+		for c, pattern := range corrupted {
+			recall := hopfield.Recall(pattern, 10, int64(c))
+			energies = append(energies, [2]float64 {hopfield.Energy(pattern), hopfield.Energy(recall)})
+			recalled = append(recalled, recall)
+		}
+		resultchan <- recalled
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Hopfield recall timed out")
+		t.Fail()
+		return
+	case recalled := <- resultchan:
+		for p, pattern := range patterns {
+			for i := range pattern {
+				if recalled[p][i] != pattern[i] {
+					t.Log("Failure - Corrupted pattern was not restored")
+					t.Log(recalled[p], pattern)
+					t.Fail()
+					return
+				}
+			}
+			if energies[p][1] >= energies[p][0] {
+				t.Log("Failure - Recall did not lower the energy")
+				t.Log(energies)
+				t.Fail()
+				return
+			}
+		}
+		for i := range weights {
+			for j := range weights {
+				if weights[i][j] != weights[j][i] {
+					t.Log("Failure - Hebbian weights are not symmetric")
+					t.Fail()
+					return
+				}
+			}
+		}
+		t.Log("Success - Hopfield network restores corrupted patterns")
+		return
+	}
+}
+
+func Test_Hopfield_Model (t *testing.T) {
+	resultchan := make(chan []float64)
+	var timeout time.Duration = 2000
+	pattern := []float64 {1, -1, -1, 1, 1, -1}
+	hopfield, _ := NewHopfield(HebbianWeights([][]float64 {pattern}))
+	defer hopfield.Close()
+	var saved bytes.Buffer
+	if err := hopfield.Save(&saved); err != nil {
+		t.Log("Failure - Hopfield network could not be saved")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	model, err := LoadModel(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Log("Failure - Saved Hopfield network is not a model")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	loaded, err := LoadHopfield(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Log("Failure - Hopfield network could not be loaded")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	defer loaded.Close()
+	network, _ := model.Network()
+	defer network.Close()
+	var predicted []float64
+
+	go func() {
+		predicted = network.Predict(pattern)
+		resultchan <- loaded.Recall([]float64 {-1, -1, -1, 1, 1, -1}, 10, 0)
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Loaded Hopfield network timed out")
+		t.Fail()
+		return
+	case recalled := <- resultchan:
+		for i := range pattern {
+			if predicted[i] != pattern[i] || recalled[i] != pattern[i] {
+				t.Log("Failure - Stored pattern is not a fixed point after loading")
+				t.Log(predicted, recalled, pattern)
+				t.Fail()
+				return
+			}
+		}
+		if math.Abs(loaded.Energy(pattern) - Energy(model.Weights[0], pattern)) > 1e-12 {
+			t.Log("Failure - Loaded weights differ from the saved model")
+			t.Fail()
+			return
+		}
+		t.Log("Success - Hopfield network round-trips through the model format")
+		return
+	}
+}
+
+func Test_CheckHopfield_Weights (t *testing.T) {
+	weights := HebbianWeights([][]float64 {{1, -1, 1}})
+	asymmetric, diagonal, ragged := copyWeights([][][]float64 {weights})[0], copyWeights([][][]float64 {weights})[0], copyWeights([][][]float64 {weights})[0]
+	asymmetric[0][1] = asymmetric[0][1] + 0.5
+	diagonal[2][2] = 0.5
+	ragged[1] = ragged[1][:2]
+	for _, rejected := range [][][]float64 {asymmetric, diagonal, ragged} {
+		hopfield, err := NewHopfield(rejected)
+		if err == nil || hopfield != nil {
+			t.Log("Failure - Hopfield network accepted asymmetric, self connected or ragged weights")
+			t.Log(rejected)
+			t.Fail()
+			return
+		}
+	}
+	if _, ok := Activations["sign"]; ok {
+		t.Log("Failure - Sign activation is offered to trainable layers")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Hopfield weights are checked for symmetry and a zero diagonal")
+}
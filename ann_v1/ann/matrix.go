@@ -0,0 +1,327 @@
+package ann
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+type Predictor interface {
+	Predict (input []float64) []float64
+}
+
+type Matrix struct {
+	LearnRate float64
+	Inputs int
+	Layers []Layer
+	Weights [][][]float64
+	Statistics [][][]float64
+	Pipeline Pipeline
+	thresholds [][][]float64
+}
+
+type Divergence struct {
+	Sample int
+	Output float64
+	Weight float64
+}
+
+func NewMatrix (learnrate float64, inputs int, layers []Layer, weights [][][]float64) *Matrix {
+	return &Matrix {LearnRate: learnrate, Inputs: inputs, Layers: layers, Weights: copyWeights(weights), Statistics: initialStatistics(layers)}
+}
+
+func copyWeights (weights [][][]float64) [][][]float64 {
+	copied := make([][][]float64, len(weights))
+	for l := range weights {
+		copied[l] = make([][]float64, len(weights[l]))
+		for j := range weights[l] {
+			copied[l][j] = append([]float64 {}, weights[l][j]...)
+		}
+	}
+	return copied
+}
+
+func (model Model) Matrix () *Matrix {
+	matrix := NewMatrix(model.LearnRate, model.Inputs, model.Layers, model.Weights)
+	matrix.Pipeline = model.Pipeline
+	for l := range model.Statistics {
+		if model.Statistics[l] != nil {matrix.Statistics[l] = copyStatistics(model.Statistics)[l]}
+	}
+	return matrix
+}
+
+func (matrix *Matrix) Model () Model {
+	return Model {
+		LearnRate: matrix.LearnRate,
+		Inputs: matrix.Inputs,
+		Layers: matrix.Layers,
+		Weights: copyWeights(matrix.Weights),
+		Statistics: copyStatistics(matrix.Statistics),
+		Pipeline: matrix.Pipeline,
+	}
+}
+
+func (matrix *Matrix) SetWeights (weights [][][]float64) {
+	matrix.Weights = copyWeights(weights)
+}
+
+func (matrix *Matrix) Save (writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(matrix.Model())
+}
+
+func (matrix *Matrix) Forward (input []float64) ([][]float64, [][]float64) {
+	signals, excitements, _ := matrix.steps([][]float64 {input}, nil, nil, false)
+	return signals[0], excitements[0]
+}
+
+func (matrix *Matrix) context (l int, signal []float64, previous [][]float64) []float64 {
+	for _, r := range recurrence(matrix.Layers, l) {
+		if r < len(previous) && previous[r] != nil {
+			signal = append(signal, previous[r]...)
+		} else {
+			signal = append(signal, make([]float64, matrix.Layers[r].Neurons)...)
+		}
+	}
+	return signal
+}
+
+func pick (rows [][][]float64, s int) [][]float64 {
+	if s < len(rows) {return rows[s]}
+	return nil
+}
+
+func at (rows [][]float64, l int) []float64 {
+	if l < len(rows) {return rows[l]}
+	return nil
+}
+
+func (matrix *Matrix) steps (inputs [][]float64, previous [][][]float64, states [][][]float64, infer bool) ([][][]float64, [][][]float64, [][][]float64) {
+	signals := make([][][]float64, len(inputs))
+	excitements := make([][][]float64, len(inputs))
+	next := make([][][]float64, len(inputs))
+	for s, input := range inputs {
+		signals[s] = [][]float64 {matrix.context(0, append(append([]float64 {}, input...), 1), pick(previous, s))}
+		excitements[s] = make([][]float64, len(matrix.Layers))
+		next[s] = make([][]float64, len(matrix.Layers))
+	}
+	for l, layer := range matrix.Layers {
+		var outputs [][]float64
+		if layer.Normalization.Kind != "" {outputs = matrix.normalize(l, signals, excitements, infer)}
+		for s := range inputs {
+			var output []float64
+			if outputs != nil {
+				output = outputs[s]
+			} else {
+				output, excitements[s][l], next[s][l] = matrix.forward(l, signals[s][l], at(pick(states, s), l))
+			}
+			output[layer.Neurons] = 1
+			if l + 1 < len(matrix.Layers) {output = matrix.context(l + 1, output, pick(previous, s))}
+			signals[s] = append(signals[s], output)
+		}
+	}
+	return signals, excitements, next
+}
+
+func (matrix *Matrix) forward (l int, signal []float64, state []float64) ([]float64, []float64, []float64) {
+	layer := matrix.Layers[l]
+	output := make([]float64, layer.Neurons + 1)
+	if layer.Cell.Name != "" {
+		excitements, next := matrix.gate(l, signal, state, output)
+		return output, excitements, next
+	}
+	if linked(matrix.Layers, l) {return output, matrix.link(l, signal, output), nil}
+	if layer.Embedding.Dimensions > 0 {
+		matrix.embed(l, signal, output)
+		return output, nil, nil
+	}
+	excitements := make([]float64, layer.Neurons)
+	weighted := make([]float64, len(signal))
+	aggregation := aggregator(layer)
+	for j := range excitements {
+		for i, value := range signal {
+			weighted[i] = value * matrix.Weights[l][j][i]
+		}
+		excitements[j] = aggregation.Function(weighted)
+		output[j] = layer.Activation.Function(excitements[j])
+	}
+	return output, excitements, nil
+}
+
+func zeroWeights (weights [][][]float64) [][][]float64 {
+	zeroed := make([][][]float64, len(weights))
+	for l := range weights {
+		zeroed[l] = make([][]float64, len(weights[l]))
+		for j := range weights[l] {
+			zeroed[l][j] = make([]float64, len(weights[l][j]))
+		}
+	}
+	return zeroed
+}
+
+func (matrix *Matrix) Backward (signals [][]float64, excitements [][]float64, errormargins []float64, adjustments [][][]float64) []float64 {
+	feedback, _, _ := matrix.backsteps([][][]float64 {signals}, [][][]float64 {excitements}, nil, [][]float64 {errormargins}, nil, nil, adjustments)
+	return feedback[0]
+}
+
+func (matrix *Matrix) backsteps (signals [][][]float64, excitements [][][]float64, states [][][]float64, errormargins [][]float64, carry [][][]float64, cellcarry [][][]float64, adjustments [][][]float64) ([][]float64, [][][]float64, [][][]float64) {
+	margins := make([][]float64, len(signals))
+	recurrent := make([][][]float64, len(signals))
+	cellmargins := make([][][]float64, len(signals))
+	for s := range signals {
+		margins[s] = errormargins[s]
+		recurrent[s] = make([][]float64, len(matrix.Layers))
+		cellmargins[s] = make([][]float64, len(matrix.Layers))
+	}
+	for l := len(matrix.Layers) - 1; l >= 0; l-- {
+		for s := range signals {
+			if carried := at(pick(carry, s), l); carried != nil {
+				summed := make([]float64, len(margins[s]))
+				for j := range summed {
+					summed[j] = margins[s][j] + carried[j]
+				}
+				margins[s] = summed
+			}
+		}
+		upstreams := make([][]float64, len(signals))
+		if matrix.Layers[l].Normalization.Kind != "" {
+			upstreams = matrix.denormalize(l, signals, excitements, margins, adjustments)
+		} else {
+			for s := range signals {
+				upstreams[s], cellmargins[s][l] = matrix.backward(l, signals[s][l], excitements[s][l], at(pick(states, s), l), margins[s], at(pick(cellcarry, s), l), adjustments)
+			}
+		}
+		fanin := matrix.Inputs
+		if l > 0 {fanin = matrix.Layers[l - 1].Neurons}
+		for s, upstream := range upstreams {
+			offset := fanin + 1
+			for _, r := range recurrence(matrix.Layers, l) {
+				if recurrent[s][r] == nil {recurrent[s][r] = make([]float64, matrix.Layers[r].Neurons)}
+				for k := range recurrent[s][r] {
+					recurrent[s][r][k] = recurrent[s][r][k] + upstream[offset + k]
+				}
+				offset = offset + matrix.Layers[r].Neurons
+			}
+			margins[s] = upstream[:fanin]
+		}
+	}
+	return margins, recurrent, cellmargins
+}
+
+func (matrix *Matrix) backward (l int, signal []float64, excitements []float64, state []float64, errormargins []float64, carry []float64, adjustments [][][]float64) ([]float64, []float64) {
+	layer := matrix.Layers[l]
+	upstream := make([]float64, len(signal))
+	if layer.Cell.Name != "" {return upstream, matrix.ungate(l, signal, excitements, state, errormargins, carry, upstream, adjustments[l])}
+	if linked(matrix.Layers, l) {
+		matrix.unlink(l, signal, excitements, errormargins, upstream, adjustments)
+		return upstream, nil
+	}
+	if layer.Embedding.Dimensions > 0 {
+		matrix.unembed(l, signal, errormargins, upstream, adjustments[l])
+		return upstream, nil
+	}
+	weighted := make([]float64, len(signal))
+	aggregation := aggregator(layer)
+	rules := plastic(matrix.Layers, l)
+	for j, errormargin := range errormargins {
+		delta := errormargin * layer.Activation.Derivative(excitements[j])
+		adjustment := matrix.LearnRate * delta
+		for i, value := range signal {
+			weighted[i] = value * matrix.Weights[l][j][i]
+		}
+		for i, value := range signal {
+			partial := aggregation.Derivative(weighted, i)
+			upstream[i] = upstream[i] + delta * partial * matrix.Weights[l][j][i]
+			if rules != nil && rules[j][i].Delta != nil {continue}
+			adjustments[l][j][i] = adjustments[l][j][i] + adjustment * partial * value
+		}
+	}
+	if rules != nil {matrix.plasticity(l, signal, excitements, rules, adjustments)}
+	return upstream, nil
+}
+
+func (matrix *Matrix) Adjust (adjustments [][][]float64) {
+	for l := range adjustments {
+		for j := range adjustments[l] {
+			for i, adjustment := range adjustments[l][j] {
+				matrix.Weights[l][j][i] = matrix.Weights[l][j][i] + adjustment
+			}
+		}
+	}
+}
+
+func (matrix *Matrix) LearnBatch (sets []TrainingSet) [][]float64 {
+	adjustments := matrix.adjustments()
+	inputs := make([][]float64, len(sets))
+	for s, set := range sets {
+		inputs[s] = matrix.Pipeline.Transform(set.Input)
+	}
+	signals, excitements, _ := matrix.steps(inputs, nil, nil, false)
+	outputs := make([][]float64, len(sets))
+	errormargins := make([][]float64, len(sets))
+	for s, set := range sets {
+		output := signals[s][len(signals[s]) - 1]
+		outputs[s] = output[:len(output) - 1]
+		errormargins[s] = make([]float64, len(outputs[s]))
+		for j := range outputs[s] {
+			errormargins[s][j] = set.Expect[j] - outputs[s][j]
+		}
+	}
+	matrix.backsteps(signals, excitements, nil, errormargins, nil, nil, adjustments)
+	matrix.Adjust(adjustments)
+	return outputs
+}
+
+func (matrix *Matrix) Learn (set TrainingSet) []float64 {
+	return matrix.LearnBatch([]TrainingSet {set})[0]
+}
+
+func (matrix *Matrix) Predict (input []float64) []float64 {
+	signals, _, _ := matrix.steps([][]float64 {matrix.Pipeline.Transform(input)}, nil, nil, true)
+	output := signals[0][len(signals[0]) - 1]
+	return output[:len(output) - 1]
+}
+
+func (matrix *Matrix) Train (regimen Regimen, epochs int) {
+	matrix.Feed(NewSequentialSource(regimen), epochs)
+}
+
+func (matrix *Matrix) TrainBatches (regimen Regimen, epochs int, size int) {
+	matrix.FeedBatches(NewSequentialSource(regimen), epochs, size)
+}
+
+func (matrix *Matrix) Feed (source DataSource, epochs int) error {
+	return matrix.FeedBatches(source, epochs, 1)
+}
+
+func (matrix *Matrix) FeedBatches (source DataSource, epochs int, size int) error {
+	for epoch := 0; epoch < epochs; epoch++ {
+		if epoch > 0 {
+			if err := source.Reset(); err != nil {return err}
+		}
+		for batch := Take(source, size); len(batch) > 0; batch = Take(source, size) {
+			matrix.LearnBatch(batch)
+		}
+		if err := failure(source); err != nil {return err}
+	}
+	return nil
+}
+
+func CrossCheck (network *Network, matrix *Matrix, regimen Regimen) []Divergence {
+	divergences := make([]Divergence, len(regimen.TrainingSets))
+	for s, set := range regimen.TrainingSets {
+		divergences[s].Sample = s
+		expected := matrix.Learn(set)
+		for j, output := range network.Learn(set) {
+			divergences[s].Output = math.Max(divergences[s].Output, math.Abs(output - expected[j]))
+		}
+		weights := network.Weights()
+		for l := range weights {
+			for j := range weights[l] {
+				for i, weight := range weights[l][j] {
+					divergences[s].Weight = math.Max(divergences[s].Weight, math.Abs(weight - matrix.Weights[l][j][i]))
+				}
+			}
+		}
+	}
+	return divergences
+}
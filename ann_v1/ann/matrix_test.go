@@ -0,0 +1,74 @@
+package ann
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_Matrix_CrossCheck_Network (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 17)
+	network, _ := NewNetwork(0.7, 2, layers, weights)
+	matrix := NewMatrix(0.7, 2, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {0, 0}, Expect: []float64 {0, 1}},
+		{Input: []float64 {0, 1}, Expect: []float64 {1, 0}},
+		{Input: []float64 {1, 0}, Expect: []float64 {1, 0}},
+		{Input: []float64 {1, 1}, Expect: []float64 {0, 1}},
+	}}
+
+	go func() {
+		var divergences []Divergence
+		for epoch := 0; epoch < 20; epoch++ {
+			divergences = append(divergences, CrossCheck(network, matrix, regimen)...)
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Matrix cross check timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Matrix engine diverged from the network")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		t.Log("Success - Matrix engine matches the network sample by sample")
+		return
+	}
+}
+
+func Test_Matrix_Model_Shared (t *testing.T) {
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	matrix := NewMatrix(0.3, 2, layers, RandomWeights(2, layers, 2))
+	matrix.Pipeline = Pipeline {&Polynomial {Degree: 1}}
+	var buffer bytes.Buffer
+	if err := matrix.Save(&buffer); err != nil {
+		t.Log("Failure - Matrix save returned an error")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	model, err := LoadModel(&buffer)
+	if err != nil {
+		t.Log("Failure - Matrix model load returned an error")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	if loaded := model.Matrix(); loaded.Predict([]float64 {1, 2})[0] != matrix.Predict([]float64 {1, 2})[0] || len(loaded.Pipeline) != 1 {
+		t.Log("Failure - Loaded matrix predicts differently")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Matrix engine shares the model format")
+}
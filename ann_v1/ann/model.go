@@ -0,0 +1,71 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type Model struct {
+	LearnRate float64
+	Inputs int
+	Layers []Layer
+	Weights [][][]float64
+	Statistics [][][]float64
+	Pipeline Pipeline
+}
+
+func registered (name string) (Activation, bool) {
+	if name == sign.Name {return sign, true}
+	activation, ok := Activations[name]
+	return activation, ok
+}
+
+func (activation Activation) MarshalJSON () ([]byte, error) {
+	if _, ok := registered(activation.Name); !ok {return nil, fmt.Errorf("ann: unregistered activation %q", activation.Name)}
+	return json.Marshal(activation.Name)
+}
+
+func (activation *Activation) UnmarshalJSON (data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {return err}
+	known, ok := registered(name)
+	if !ok {return fmt.Errorf("ann: unregistered activation %q", name)}
+	*activation = known
+	return nil
+}
+
+func (network *Network) Model () Model {
+	return Model {
+		LearnRate: network.LearnRate,
+		Inputs: network.Inputs,
+		Layers: network.Layers,
+		Weights: network.Weights(),
+		Statistics: network.Statistics(),
+		Pipeline: network.Pipeline,
+	}
+}
+
+func (model Model) Network () (*Network, error) {
+	network, err := NewNetwork(model.LearnRate, model.Inputs, model.Layers, model.Weights)
+	if err != nil {return nil, err}
+	network.Pipeline = model.Pipeline
+	network.SetStatistics(model.Statistics)
+	return network, nil
+}
+
+func (network *Network) Save (writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(network.Model())
+}
+
+func LoadModel (reader io.Reader) (Model, error) {
+	var model Model
+	err := json.NewDecoder(reader).Decode(&model)
+	return model, err
+}
+
+func Load (reader io.Reader) (*Network, error) {
+	model, err := LoadModel(reader)
+	if err != nil {return nil, err}
+	return model.Network()
+}
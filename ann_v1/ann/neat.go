@@ -0,0 +1,502 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const devneat bool = false
+
+type NodeGene struct {
+	Id int
+	Kind string
+	Bias float64
+}
+
+type ConnectionGene struct {
+	Innovation int
+	In int
+	Out int
+	Weight float64
+	Enabled bool
+}
+
+type Genome struct {
+	Nodes []NodeGene
+	Connections []ConnectionGene
+	Activation Activation
+	Fitness float64
+}
+
+type Species struct {
+	Representative Genome
+	Members []int
+	Best float64
+	Stale int
+}
+
+type NEAT struct {
+	Inputs int
+	Outputs int
+	Population int
+	Activation Activation
+	Compatibility float64
+	Excess float64
+	Disjoint float64
+	Weight float64
+	Survival float64
+	Stagnation int
+	WeightRate float64
+	ConnectionRate float64
+	NodeRate float64
+	Target float64
+	Seed int64
+}
+
+type Population struct {
+	NEAT NEAT
+	Genomes []Genome
+	Species []Species
+	Generation int
+	Champion Genome
+	innovations map[edge]int
+	splits map[int]int
+	nodes int
+	random *rand.Rand
+}
+
+type Phenotype struct {
+	sources []SignalPeripherals
+	outputs []chan Signal
+	faults chan Fault
+	cancelchan chan struct{}
+}
+
+type edge struct {
+	in int
+	out int
+}
+
+func (neat NEAT) defaults () NEAT {
+	if neat.Population <= 0 {neat.Population = 150}
+	if neat.Activation.Function == nil {neat.Activation = Activations["sigmoid"]}
+	if neat.Compatibility <= 0 {neat.Compatibility = 3}
+	if neat.Excess <= 0 {neat.Excess = 1}
+	if neat.Disjoint <= 0 {neat.Disjoint = 1}
+	if neat.Weight <= 0 {neat.Weight = 0.4}
+	if neat.Survival <= 0 {neat.Survival = 0.2}
+	if neat.Stagnation <= 0 {neat.Stagnation = 15}
+	if neat.WeightRate <= 0 {neat.WeightRate = 0.8}
+	if neat.ConnectionRate <= 0 {neat.ConnectionRate = 0.05}
+	if neat.NodeRate <= 0 {neat.NodeRate = 0.03}
+	return neat
+}
+
+func NewPopulation (neat NEAT) *Population {
+	neat = neat.defaults()
+	population := &Population {NEAT: neat, innovations: map[edge]int {}, splits: map[int]int {}, nodes: neat.Inputs + neat.Outputs, random: rand.New(rand.NewSource(neat.Seed))}
+	for g := 0; g < neat.Population; g++ {
+		genome := Genome {Activation: neat.Activation}
+		for id := 0; id < neat.Inputs + neat.Outputs; id++ {
+			node := NodeGene {Id: id, Kind: "input"}
+			if id >= neat.Inputs {node.Kind, node.Bias = "output", population.random.Float64()*2 - 1}
+			genome.Nodes = append(genome.Nodes, node)
+		}
+		for in := 0; in < neat.Inputs; in++ {
+			for out := neat.Inputs; out < neat.Inputs + neat.Outputs; out++ {
+				genome.Connections = append(genome.Connections, ConnectionGene {Innovation: population.innovation(in, out), In: in, Out: out, Weight: population.random.Float64()*2 - 1, Enabled: true})
+			}
+		}
+		population.Genomes = append(population.Genomes, genome)
+	}
+	return population
+}
+
+func (population *Population) innovation (in int, out int) int {
+	key := edge {in: in, out: out}
+	if innovation, ok := population.innovations[key]; ok {return innovation}
+	population.innovations[key] = len(population.innovations)
+	return population.innovations[key]
+}
+
+func (genome Genome) Copy () Genome {
+	copied := genome
+	copied.Nodes = append([]NodeGene {}, genome.Nodes...)
+	copied.Connections = append([]ConnectionGene {}, genome.Connections...)
+	return copied
+}
+
+func (genome Genome) node (id int) int {
+	for n, node := range genome.Nodes {
+		if node.Id == id {return n}
+	}
+	return -1
+}
+
+func (genome Genome) connected (in int, out int) bool {
+	for _, connection := range genome.Connections {
+		if connection.In == in && connection.Out == out {return true}
+	}
+	return false
+}
+
+func (genome Genome) reaches (from int, to int) bool {
+	visited := map[int]bool {}
+	frontier := []int {from}
+	for len(frontier) > 0 {
+		current := frontier[len(frontier) - 1]
+		frontier = frontier[:len(frontier) - 1]
+		if current == to {return true}
+		if visited[current] {continue}
+		visited[current] = true
+		for _, connection := range genome.Connections {
+			if connection.In == current {frontier = append(frontier, connection.Out)}
+		}
+	}
+	return false
+}
+
+func (genome Genome) order () []int {
+	indegrees := map[int]int {}
+	for _, connection := range genome.Connections {
+		if connection.Enabled {indegrees[connection.Out]++}
+	}
+	var order, ready []int
+	for _, node := range genome.Nodes {
+		if indegrees[node.Id] == 0 {ready = append(ready, node.Id)}
+	}
+	for len(ready) > 0 {
+		id := ready[0]
+		ready, order = ready[1:], append(order, id)
+		for _, connection := range genome.Connections {
+			if !connection.Enabled || connection.In != id {continue}
+			indegrees[connection.Out]--
+			if indegrees[connection.Out] == 0 {ready = append(ready, connection.Out)}
+		}
+	}
+	return order
+}
+
+func (genome Genome) Predict (input []float64) []float64 {
+	values := map[int]float64 {}
+	for _, id := range genome.order() {
+		node := genome.Nodes[genome.node(id)]
+		if node.Kind == "input" {
+			values[id] = input[id]
+			continue
+		}
+		var sum float64
+		for _, connection := range genome.Connections {
+			if connection.Enabled && connection.Out == id {sum = sum + values[connection.In] * connection.Weight}
+		}
+		values[id] = genome.Activation.Function(sum + node.Bias)
+	}
+	var outputs []float64
+	for _, node := range genome.Nodes {
+		if node.Kind == "output" {outputs = append(outputs, values[node.Id])}
+	}
+	return outputs
+}
+
+func (genome Genome) Distance (other Genome, neat NEAT) float64 {
+	neat = neat.defaults()
+	genes := map[int]ConnectionGene {}
+	var last, otherlast int
+	for _, connection := range other.Connections {
+		genes[connection.Innovation] = connection
+		if connection.Innovation > otherlast {otherlast = connection.Innovation}
+	}
+	var excess, disjoint, matching int
+	var difference float64
+	for _, connection := range genome.Connections {
+		if connection.Innovation > last {last = connection.Innovation}
+		match, ok := genes[connection.Innovation]
+		if ok {
+			matching++
+			difference = difference + math.Abs(connection.Weight - match.Weight)
+			delete(genes, connection.Innovation)
+		} else if connection.Innovation > otherlast {
+			excess++
+		} else {
+			disjoint++
+		}
+	}
+	for innovation := range genes {
+		if innovation > last {excess++} else {disjoint++}
+	}
+	size := math.Max(float64(len(genome.Connections)), float64(len(other.Connections)))
+	if size < 20 {size = 1}
+	distance := (neat.Excess * float64(excess) + neat.Disjoint * float64(disjoint)) / size
+	if matching > 0 {distance = distance + neat.Weight * difference / float64(matching)}
+	return distance
+}
+
+func (genome Genome) Phenotype () *Phenotype {
+	phenotype := &Phenotype {faults: make(chan Fault, 64), cancelchan: make(chan struct{})}
+	peripherals := map[int]SignalPeripherals {}
+	lanes := map[int][]chan Signal {}
+	counts := map[int]int {}
+	for _, node := range genome.Nodes {
+		peripherals[node.Id] = NewSignalPeripherals()
+		if node.Kind == "input" {phenotype.sources = append(phenotype.sources, peripherals[node.Id])}
+	}
+	for _, connection := range genome.Connections {
+		if !connection.Enabled {continue}
+		lane := make(chan Signal)
+		lanes[connection.In] = append(lanes[connection.In], lane)
+		synapse := SignalPeripherals {Input: lane, Output: peripherals[connection.Out].Input}
+		go TaggedSynapse (connection.Weight, Tag {Source: counts[connection.Out]}, synapse, Probe {}, phenotype.cancelchan)
+		counts[connection.Out]++
+	}
+	bias := NewSignalPeripherals()
+	var biaslanes []chan Signal
+	for _, node := range genome.Nodes {
+		if node.Kind == "input" {continue}
+		lane := make(chan Signal)
+		biaslanes = append(biaslanes, lane)
+		synapse := SignalPeripherals {Input: lane, Output: peripherals[node.Id].Input}
+		go TaggedSynapse (node.Bias, Tag {Source: counts[node.Id]}, synapse, Probe {}, phenotype.cancelchan)
+		counts[node.Id]++
+	}
+	phenotype.sources = append(phenotype.sources, bias)
+	go Terminals (bias.Input, biaslanes, phenotype.cancelchan)
+	for _, node := range genome.Nodes {
+		if node.Kind == "input" {
+			go Terminals (peripherals[node.Id].Input, lanes[node.Id], phenotype.cancelchan)
+			continue
+		}
+		if node.Kind == "output" {
+			output := make(chan Signal)
+			phenotype.outputs = append(phenotype.outputs, output)
+			lanes[node.Id] = append(lanes[node.Id], output)
+		}
+		internals := NewSignalPeripherals()
+		go TaggedDendrite (counts[node.Id], peripherals[node.Id].Input, internals.Input, phenotype.faults, phenotype.cancelchan)
+		go SignalNucleus (0, genome.Activation, internals, phenotype.cancelchan)
+		go Terminals (internals.Output, lanes[node.Id], phenotype.cancelchan)
+	}
+	return phenotype
+}
+
+func (phenotype *Phenotype) PredictBatch (inputs [][]float64) [][]float64 {
+	signals := Transpose(inputs, len(phenotype.sources) - 1)
+	for i, source := range phenotype.sources {
+		signal := Fill(len(inputs), 1)
+		if i < len(signals) {signal = signals[i]}
+		signal.Infer = true
+		source.Input <- signal
+	}
+	outputs := make([]Signal, len(phenotype.outputs))
+	for j, output := range phenotype.outputs {
+		outputs[j] = <- output
+	}
+	return Rows(outputs)
+}
+
+func (phenotype *Phenotype) Predict (input []float64) []float64 {
+	return phenotype.PredictBatch([][]float64 {input})[0]
+}
+
+func (phenotype *Phenotype) Close () {
+	close(phenotype.cancelchan)
+}
+
+func (population *Population) Evaluate (regimen Regimen) {
+	type scored struct {
+		genome int
+		fitness float64
+	}
+	results := make(chan scored)
+	for g, genome := range population.Genomes {
+		go func (g int, genome Genome) {
+			phenotype := genome.Phenotype()
+			defer phenotype.Close()
+			results <- scored {genome: g, fitness: 1 / (1 + Evaluate(phenotype, regimen).Loss)}
+		}(g, genome)
+	}
+	for range population.Genomes {
+		result := <- results
+		population.Genomes[result.genome].Fitness = result.fitness
+	}
+	for _, genome := range population.Genomes {
+		if genome.Fitness > population.Champion.Fitness {population.Champion = genome.Copy()}
+	}
+}
+
+func (population *Population) Speciate () {
+	for s := range population.Species {
+		population.Species[s].Members = nil
+	}
+	for g, genome := range population.Genomes {
+		placed := false
+		for s := range population.Species {
+			if genome.Distance(population.Species[s].Representative, population.NEAT) < population.NEAT.Compatibility {
+				population.Species[s].Members = append(population.Species[s].Members, g)
+				placed = true
+				break
+			}
+		}
+		if !placed {population.Species = append(population.Species, Species {Representative: genome, Members: []int {g}, Best: math.Inf(-1)})}
+	}
+	var species []Species
+	for _, kind := range population.Species {
+		if len(kind.Members) == 0 {continue}
+		kind.Representative = population.Genomes[kind.Members[population.random.Intn(len(kind.Members))]]
+		species = append(species, kind)
+	}
+	population.Species = species
+}
+
+func (population *Population) perturb (weight float64) float64 {
+	if population.random.Float64() < 0.9 {return weight + population.random.NormFloat64() * 0.5}
+	return population.random.Float64()*4 - 2
+}
+
+func (population *Population) connect (genome Genome) Genome {
+	for attempt := 0; attempt < 20; attempt++ {
+		in, out := genome.Nodes[population.random.Intn(len(genome.Nodes))], genome.Nodes[population.random.Intn(len(genome.Nodes))]
+		if in.Kind == "output" || out.Kind == "input" || in.Id == out.Id || genome.connected(in.Id, out.Id) || genome.reaches(out.Id, in.Id) {continue}
+		genome.Connections = append(genome.Connections, ConnectionGene {Innovation: population.innovation(in.Id, out.Id), In: in.Id, Out: out.Id, Weight: population.random.Float64()*2 - 1, Enabled: true})
+		return genome
+	}
+	return genome
+}
+
+func (population *Population) split (genome Genome) Genome {
+	var enabled []int
+	for c, connection := range genome.Connections {
+		if connection.Enabled {enabled = append(enabled, c)}
+	}
+	if len(enabled) == 0 {return genome}
+	c := enabled[population.random.Intn(len(enabled))]
+	connection := genome.Connections[c]
+	id, ok := population.splits[connection.Innovation]
+	if !ok || genome.node(id) >= 0 {
+		id = population.nodes
+		population.nodes++
+		if !ok {population.splits[connection.Innovation] = id}
+	}
+	genome.Connections[c].Enabled = false
+	genome.Nodes = append(genome.Nodes, NodeGene {Id: id, Kind: "hidden"})
+	genome.Connections = append(genome.Connections,
+		ConnectionGene {Innovation: population.innovation(connection.In, id), In: connection.In, Out: id, Weight: 1, Enabled: true},
+		ConnectionGene {Innovation: population.innovation(id, connection.Out), In: id, Out: connection.Out, Weight: connection.Weight, Enabled: true},
+	)
+	return genome
+}
+
+func (population *Population) mutate (genome Genome) Genome {
+	if population.random.Float64() < population.NEAT.WeightRate {
+		for c := range genome.Connections {
+			genome.Connections[c].Weight = population.perturb(genome.Connections[c].Weight)
+		}
+		for n := range genome.Nodes {
+			if genome.Nodes[n].Kind != "input" {genome.Nodes[n].Bias = population.perturb(genome.Nodes[n].Bias)}
+		}
+	}
+	if population.random.Float64() < population.NEAT.ConnectionRate {genome = population.connect(genome)}
+	if population.random.Float64() < population.NEAT.NodeRate {genome = population.split(genome)}
+	return genome
+}
+
+func (population *Population) crossover (fitter Genome, other Genome) Genome {
+	child := fitter.Copy()
+	child.Fitness = 0
+	genes := map[int]ConnectionGene {}
+	for _, connection := range other.Connections {
+		genes[connection.Innovation] = connection
+	}
+	for c, connection := range child.Connections {
+		match, ok := genes[connection.Innovation]
+		if !ok {continue}
+		if population.random.Float64() < 0.5 {child.Connections[c].Weight = match.Weight}
+		if !connection.Enabled || !match.Enabled {child.Connections[c].Enabled = population.random.Float64() >= 0.75}
+	}
+	for n, node := range child.Nodes {
+		if m := other.node(node.Id); m >= 0 && population.random.Float64() < 0.5 {child.Nodes[n].Bias = other.Nodes[m].Bias}
+	}
+	return child
+}
+
+func (population *Population) Reproduce () {
+	neat := population.NEAT
+	best := math.Inf(-1)
+	for _, genome := range population.Genomes {
+		best = math.Max(best, genome.Fitness)
+	}
+	var survivors []Species
+	for _, species := range population.Species {
+		top := math.Inf(-1)
+		for _, g := range species.Members {
+			top = math.Max(top, population.Genomes[g].Fitness)
+		}
+		if top > species.Best {species.Best, species.Stale = top, 0} else {species.Stale++}
+		if species.Stale >= neat.Stagnation && top < best {continue}
+		survivors = append(survivors, species)
+	}
+	var floor float64
+	for _, species := range survivors {
+		for _, g := range species.Members {
+			floor = math.Min(floor, population.Genomes[g].Fitness)
+		}
+	}
+	shares := make([]float64, len(survivors))
+	var total float64
+	for s, species := range survivors {
+		for _, g := range species.Members {
+			shares[s] = shares[s] + (population.Genomes[g].Fitness - floor) / float64(len(species.Members))
+		}
+		total = total + shares[s]
+	}
+	if total == 0 {
+		for s := range shares {
+			shares[s] = 1
+		}
+		total = float64(len(shares))
+	}
+	counts := make([]int, len(survivors))
+	allotted, largest := 0, 0
+	for s := range survivors {
+		counts[s] = int(shares[s] / total * float64(neat.Population))
+		allotted = allotted + counts[s]
+		if shares[s] > shares[largest] {largest = s}
+	}
+	counts[largest] = counts[largest] + neat.Population - allotted
+	var next []Genome
+	for s, species := range survivors {
+		members := append([]int {}, species.Members...)
+		sort.Slice(members, func (a int, b int) bool {return population.Genomes[members[a]].Fitness > population.Genomes[members[b]].Fitness})
+		born := 0
+		if len(members) > 5 && counts[s] > 0 {
+			next = append(next, population.Genomes[members[0]].Copy())
+			born++
+		}
+		pool := members[:int(math.Max(1, math.Ceil(neat.Survival * float64(len(members)))))]
+		for ; born < counts[s]; born++ {
+			parent := population.Genomes[pool[population.random.Intn(len(pool))]]
+			child := parent.Copy()
+			if len(pool) > 1 && population.random.Float64() < 0.75 {
+				other := population.Genomes[pool[population.random.Intn(len(pool))]]
+				if other.Fitness > parent.Fitness {parent, other = other, parent}
+				child = population.crossover(parent, other)
+			}
+			next = append(next, population.mutate(child))
+		}
+	}
+	population.Species, population.Genomes = survivors, next
+	population.Generation++
+}
+
+func (population *Population) Evolve (regimen Regimen, generations int) Genome {
+	for generation := 0; generation < generations; generation++ {
+		population.Evaluate(regimen)
+		if devneat {fmt.Printf("\n%v: Generation %d champion fitness [%f] across %d species...\n", time.Now(), population.Generation, population.Champion.Fitness, len(population.Species))}
+		if population.NEAT.Target > 0 && population.Champion.Fitness >= population.NEAT.Target {break}
+		population.Speciate()
+		population.Reproduce()
+	}
+	return population.Champion
+}
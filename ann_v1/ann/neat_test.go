@@ -0,0 +1,160 @@
+package ann
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+var xorRegimen = Regimen {TrainingSets: []TrainingSet {
+	{Input: []float64 {0, 0}, Expect: []float64 {0}},
+	{Input: []float64 {0, 1}, Expect: []float64 {1}},
+	{Input: []float64 {1, 0}, Expect: []float64 {1}},
+	{Input: []float64 {1, 1}, Expect: []float64 {0}},
+}}
+
+func Test_Genome_Phenotype_Predict (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 1000
+	population := NewPopulation(NEAT {Inputs: 2, Outputs: 2, Population: 1, Seed: 5})
+	genome := population.Genomes[0]
+	for m := 0; m < 12; m++ {
+		genome = population.connect(population.split(genome))
+	}
+
+	go func() {
+		var divergence float64
+		phenotype := genome.Phenotype()
+		defer phenotype.Close()
+		for _, set := range xorRegimen.TrainingSets {
+			expected := genome.Predict(set.Input)
+			for j, output := range phenotype.Predict(set.Input) {
+				divergence = math.Max(divergence, math.Abs(output - expected[j]))
+			}
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Genome phenotype timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-12 || len(genome.Nodes) < 10 {
+			t.Log("Failure - Genome phenotype diverged from the genome")
+			t.Log(divergence, len(genome.Nodes))
+			t.Fail()
+			return
+		}
+		t.Log("Success - Genome phenotype matches the genome")
+		return
+	}
+}
+
+func Test_Genome_Distance_Crossover (t *testing.T) {
+	population := NewPopulation(NEAT {Inputs: 2, Outputs: 1, Population: 2, Seed: 9})
+	first, second := population.Genomes[0], population.split(population.Genomes[1].Copy())
+	if first.Distance(first, population.NEAT) != 0 || first.Distance(second, population.NEAT) < 2 {
+		t.Log("Failure - Genome distance ignores structural differences")
+		t.Log(first.Distance(second, population.NEAT))
+		t.Fail()
+		return
+	}
+	child := population.crossover(second, first)
+	for c, connection := range child.Connections {
+		if connection.Innovation != second.Connections[c].Innovation || connection.In != second.Connections[c].In || connection.Out != second.Connections[c].Out {
+			t.Log("Failure - Crossover did not inherit the fitter parent's structure")
+			t.Log(child)
+			t.Fail()
+			return
+		}
+	}
+	if first.Connections[1].Innovation != population.Genomes[1].Connections[1].Innovation || population.innovation(1, 2) != first.Connections[1].Innovation {
+		t.Log("Failure - Matching connections received different innovation numbers")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Genomes measure distance and cross over by innovation number")
+}
+
+func Test_Population_Reproduce_Fitness (t *testing.T) {
+	resultchan := make(chan []int)
+	var timeout time.Duration = 1000
+	population := NewPopulation(NEAT {Inputs: 2, Outputs: 1, Population: 20, Seed: 3})
+	for g := 0; g < 10; g++ {
+		population.Genomes[g] = population.split(population.Genomes[g])
+	}
+
+	go func() {
+		var sizes []int
+		for _, fitness := range []func (g int) float64 {
+			func (g int) float64 {return 0},
+			func (g int) float64 {return -1 - float64(g)},
+		} {
+			for g := range population.Genomes {
+				population.Genomes[g].Fitness = fitness(g)
+			}
+			population.Speciate()
+			population.Reproduce()
+			sizes = append(sizes, len(population.Genomes))
+		}
+		resultchan <- sizes
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Reproduction stalled on zero or negative fitness")
+		t.Fail()
+		return
+	case sizes := <- resultchan:
+		for _, size := range sizes {
+			if size != 20 {
+				t.Log("Failure - Reproduction lost the population size on zero or negative fitness")
+				t.Log(sizes)
+				t.Fail()
+				return
+			}
+		}
+		t.Log("Success - Reproduction shares offspring on zero and negative fitness")
+		return
+	}
+}
+
+func Test_Population_Evolve_XOR (t *testing.T) {
+	resultchan := make(chan Genome)
+	var timeout time.Duration = 20000
+	population := NewPopulation(NEAT {Inputs: 2, Outputs: 1, Population: 150, Target: 0.95, Seed: 1})
+
+	go func() {
+		resultchan <- population.Evolve(xorRegimen, 300)
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Neuroevolution timed out")
+		t.Fail()
+		return
+	case champion := <- resultchan:
+		if champion.Fitness < 0.95 {
+			t.Log("Failure - Neuroevolution did not solve XOR")
+			t.Log(population.Generation, champion.Fitness)
+			t.Fail()
+			return
+		}
+		var buffer bytes.Buffer
+		json.NewEncoder(&buffer).Encode(champion)
+		var loaded Genome
+		if err := json.NewDecoder(&buffer).Decode(&loaded); err != nil || 1 / (1 + Evaluate(loaded, xorRegimen).Loss) != champion.Fitness {
+			t.Log("Failure - Champion genome lost in serialization")
+			t.Log(err)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Neuroevolution solved XOR")
+		t.Log(population.Generation, len(champion.Nodes), len(population.Species))
+		return
+	}
+}
@@ -3,6 +3,7 @@ package ann
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 	"fmt"
 )
@@ -12,19 +13,19 @@ const devnetwork bool = true
 var TestSet = Regimen {
 	TrainingSets: []TrainingSet {
 		{
-			Input: 1,
-			Expect: 1,
+			Input: []float64 {1},
+			Expect: []float64 {1},
 		},
 		{
-			Input: 0,
-			Expect: 0,
+			Input: []float64 {0},
+			Expect: []float64 {0},
 		},
 	},
 }
 
 type TrainingSet struct {
-	Input float64
-	Expect float64
+	Input []float64
+	Expect []float64
 }
 
 type Regimen struct {
@@ -51,45 +52,617 @@ func Init () {
 	}
 }
 
-func ErrorCatch (peripherals Peripherals, expectchan chan float64, cancelchan chan struct{}) {
+func ErrorCatch (peripherals SignalPeripherals, expectchan chan Signal, cancelchan chan struct{}) {
 	for {
 		select {
 		case result := <- peripherals.Output:
-			if devnetwork {fmt.Printf("\n%v: Result was [%d]...\n", time.Now(), result)}
+			if devnetwork {fmt.Printf("\n%v: Result was %v...\n", time.Now(), result.Values)}
 			expected := <- expectchan
-			if devnetwork {fmt.Printf("\n%v: Expected [%d]...\n", time.Now(), expected)}
-			peripherals.Upfeed <- expected - result
+			if devnetwork {fmt.Printf("\n%v: Expected %v...\n", time.Now(), expected.Values)}
+			errormargin := Signal {Sample: result.Sample, Step: result.Step, Values: make([]float64, len(result.Values))}
+			for b, value := range result.Values {
+				if b < len(expected.Values) {errormargin.Values[b] = expected.Values[b] - value}
+			}
+			peripherals.Upfeed <- errormargin
 		case <- cancelchan:
 			return
 		}
 	}
 }
 
-func StaticInput (cycles int, regimen Regimen, peripherals Peripherals, expectchan chan float64, cancelchan chan struct{}) {
-	rand.Seed(time.Now().UTC().UnixNano())
-	sets := regimen.TrainingSets
-	for {
-		set := rand.Intn(len(sets))
+func StaticInput (cycles int, regimen Regimen, peripherals SignalPeripherals, expectchan chan Signal, cancelchan chan struct{}) {
+	SourceInput(cycles, NewRandomSource(regimen, time.Now().UTC().UnixNano()), peripherals, expectchan, cancelchan)
+}
+
+func SourceInput (cycles int, source DataSource, peripherals SignalPeripherals, expectchan chan Signal, cancelchan chan struct{}) {
+	for sample := 0; ; sample++ {
+		set, ok := source.Next()
+		if !ok {
+			if err := source.Reset(); err != nil {
+				if devsource {fmt.Printf("\n%v: Source cannot rewind: %v...\n", time.Now(), err)}
+				close(cancelchan)
+				return
+			}
+			if set, ok = source.Next(); !ok {
+				if devsource {fmt.Printf("\n%v: Source exhausted...\n", time.Now())}
+				close(cancelchan)
+				return
+			}
+		}
+		if len(set.Input) == 0 {
+			if devsource {fmt.Printf("\n%v: Source gave a set without inputs...\n", time.Now())}
+			close(cancelchan)
+			return
+		}
 		select {
-		case peripherals.Input <- sets[set].Input:
-			if devnetwork {fmt.Printf("\n%v: Input out...\n", time.Now())}
-			expectchan <- sets[set].Expect
-			if devnetwork {fmt.Printf("\n%v: Expected out...\n", time.Now())}
+		case peripherals.Input <- Signal {Sample: sample, Values: []float64 {set.Input[0]}}:
+			if devsource {fmt.Printf("\n%v: Input out...\n", time.Now())}
+			for i, input := range set.Input[1:] {
+				if !PushSignalOrCancel(Signal {Sample: sample, Source: i + 1, Values: []float64 {input}}, peripherals.Input, cancelchan) {return}
+			}
+			for e, expect := range set.Expect {
+				if !PushSignalOrCancel(Signal {Sample: sample, Source: e, Values: []float64 {expect}}, expectchan, cancelchan) {return}
+			}
+			if devsource {fmt.Printf("\n%v: Expected out...\n", time.Now())}
 		case <- peripherals.Downfeed:
-			if devnetwork {fmt.Printf("\n%v: BROKEN...\n", time.Now())}
+			if devsource {fmt.Printf("\n%v: BROKEN...\n", time.Now())}
 			continue
 		case <- cancelchan:
 			return
 		}
 		cycles--
 		if cycles < 0 {
-			if devnetwork {fmt.Printf("\n%v: Closing training session...\n")}
+			if devsource {fmt.Printf("\n%v: Closing training session...\n", time.Now())}
 			close(cancelchan)
 			return
 		}
 	}
 }
 
-func NewLayer (synapses Synapses) {
+type Layer struct {
+	Neurons int
+	Activation Activation
+	Aggregation Aggregation
+	Recurrent []int
+	Cell Cell
+	Convolution Convolution
+	Tie *Tie
+	Normalization Normalization
+	Embedding Embedding
+	Mask [][]bool
+	Rules [][]Rule
+}
+
+type Sample struct {
+	Id int
+	Input []float64
+}
+
+type Prediction struct {
+	Id int
+	Output []float64
+}
+
+type Network struct {
+	LearnRate float64
+	Inputs int
+	Layers []Layer
+	Pipeline Pipeline
+	sources []SignalPeripherals
+	outputs []SignalPeripherals
+	probes [][][]Probe
+	statistics []Statistics
+	tables []Table
+	clocks []chan Tick
+	nodes [][]*junction
+	biases []*junction
+	delays [][]*junction
+	splices [][][]*splice
+	branches map[chan struct{}]bool
+	faults chan Fault
+	sample int
+	pending int
+	cancelchan chan struct{}
+}
+
+func RandomWeights (inputs int, layers []Layer, seed int64) [][][]float64 {
+	random := rand.New(rand.NewSource(seed))
+	weights := make([][][]float64, len(layers))
+	for l, layer := range layers {
+		if layer.Convolution.Kernel > 0 {
+			weights[l] = convolutionWeights(layer.Convolution, random)
+			continue
+		}
+		if layer.Tie != nil {
+			weights[l] = tiedWeights(layer)
+			continue
+		}
+		if layer.Normalization.Kind != "" {
+			weights[l] = normalizationWeights(layer)
+			continue
+		}
+		if layer.Embedding.Dimensions > 0 {
+			weights[l] = embeddingWeights(layer.Embedding, random)
+			continue
+		}
+		weights[l] = make([][]float64, layer.Neurons)
+		for j := range weights[l] {
+			for _, span := range spans(inputs, layers, l) {
+				for range span {
+					weights[l][j] = append(weights[l][j], (random.Float64()*2 - 1) / math.Sqrt(float64(len(span))))
+				}
+			}
+		}
+	}
+	return weights
+}
+
+type Fan struct {
+	Lanes chan []chan Signal
+	Live chan []bool
+}
+
+func NewFan () Fan {
+	return Fan {Lanes: make(chan []chan Signal), Live: make(chan []bool)}
+}
+
+func live (lanes []chan Signal) []bool {
+	present := make([]bool, len(lanes))
+	for i, lane := range lanes {
+		present[i] = lane != nil
+	}
+	return present
+}
+
+func Source (peripherals SignalPeripherals, lanes []chan Signal, fanout Fan, faultchan chan Fault, cancelchan chan struct{}) {
+	go LiveTerminals (peripherals.Input, lanes, fanout.Lanes, cancelchan)
+	go LiveDendrite (live(lanes), Aggregations["sum"], peripherals.Upfeed, peripherals.Downfeed, nil, fanout.Live, faultchan, cancelchan)
+}
+
+func Terminals (inputchan chan Signal, outputchans []chan Signal, cancelchan chan struct{}) {
+	LiveTerminals(inputchan, outputchans, nil, cancelchan)
+}
+
+func LiveTerminals (inputchan chan Signal, outputchans []chan Signal, lanechan chan []chan Signal, cancelchan chan struct{}) {
+	for {
+		select {
+		case outputchans = <- lanechan:
+		case input := <- inputchan:
+			for _, outputchan := range outputchans {
+				if outputchan == nil {continue}
+				if !PushSignalOrCancel(input, outputchan, cancelchan) {return}
+			}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewTerminalNeuron (learnrate float64, layer Layer, peripherals SignalPeripherals, lanes []chan Signal, terminals []chan Signal, fanout Fan, fanin Fan, faultchan chan Fault, cancelchan chan struct{}) {
+	internals := NewSignalPeripherals()
+	aggregation := aggregator(layer)
+
+	go SignalNucleus (learnrate, layer.Activation, internals, cancelchan)
+	go LiveTerminals (internals.Output, lanes, fanout.Lanes, cancelchan)
+	if aggregation.Name == "sum" {
+		go LiveDendrite (live(terminals), aggregation, peripherals.Input, internals.Input, nil, fanin.Live, faultchan, cancelchan)
+		go LiveTerminals (internals.Downfeed, terminals, fanin.Lanes, cancelchan)
+	} else {
+		partials := make(chan []Signal)
+		go LiveDendrite (live(terminals), aggregation, peripherals.Input, internals.Input, partials, fanin.Live, faultchan, cancelchan)
+		go LiveScatter (internals.Downfeed, partials, terminals, fanin.Lanes, cancelchan)
+	}
+	go LiveDendrite (live(lanes), Aggregations["sum"], peripherals.Upfeed, internals.Upfeed, nil, fanout.Live, faultchan, cancelchan)
+}
+
+func NewNetwork (learnrate float64, inputs int, layers []Layer, weights [][][]float64) (*Network, error) {
+	if err := CheckTies(inputs, layers); err != nil {return nil, err}
+	if err := CheckConvolutions(inputs, layers); err != nil {return nil, err}
+	if err := CheckNormalizations(inputs, layers); err != nil {return nil, err}
+	if err := CheckEmbeddings(inputs, layers, weights); err != nil {return nil, err}
+	network := &Network {LearnRate: learnrate, Inputs: inputs, faults: make(chan Fault, 64)}
+	network.wire(layers, weights)
+	return network, nil
+}
+
+func (network *Network) dense (layer Layer, upstream []SignalPeripherals, lanes [][]chan Signal, weights [][]float64) ([]SignalPeripherals, [][]chan Signal, [][]*splice) {
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	terminals := make([][]chan Signal, layer.Neurons)
+	splices := make([][]*splice, layer.Neurons)
+	for j := range neurons {
+		neurons[j] = NewSignalPeripherals()
+		splices[j] = make([]*splice, len(upstream))
+		for i, source := range upstream {
+			if layer.Mask != nil && !layer.Mask[j][i] {continue}
+			lanes[i], terminals[j], splices[j][i] = network.splice(weights[j][i], source, lanes[i], neurons[j], terminals[j])
+		}
+	}
+	return neurons, terminals, splices
+}
+
+func (network *Network) wire (layers []Layer, weights [][][]float64) {
+	learnrate, inputs := network.LearnRate, network.Inputs
+	network.Layers, network.cancelchan, network.branches = layers, make(chan struct{}), map[chan struct{}]bool {}
+	network.sources, network.outputs, network.probes, network.statistics, network.tables, network.clocks = nil, nil, nil, nil, nil, nil
+	network.nodes, network.biases, network.delays, network.splices = nil, nil, make([][]*junction, len(layers)), nil
+	delays := make([][]SignalPeripherals, len(layers))
+	delaylanes := make([][][]chan Signal, len(layers))
+	for l := range layers {
+		for _, r := range recurrence(layers, l) {
+			if delays[r] != nil {continue}
+			delays[r] = make([]SignalPeripherals, layers[r].Neurons)
+			delaylanes[r] = make([][]chan Signal, layers[r].Neurons)
+			network.delays[r] = make([]*junction, layers[r].Neurons)
+			for k := range delays[r] {
+				delays[r][k] = NewSignalPeripherals()
+				network.delays[r][k] = &junction {node: delays[r][k], fanout: NewFan(), cancelchan: network.branch()}
+			}
+		}
+	}
+	parameters, fixed := network.share(inputs, layers, weights)
+	upstream := make([]SignalPeripherals, inputs)
+	for i := range upstream {
+		upstream[i] = NewSignalPeripherals()
+	}
+	network.sources = append(network.sources, upstream...)
+	var terminals [][][]chan Signal
+	var channels, tapped [][]chan Signal
+	for l, layer := range layers {
+		bias := NewSignalPeripherals()
+		network.sources = append(network.sources, bias)
+		upstream = append(upstream, bias)
+		width := len(upstream)
+		lanes := make([][]chan Signal, width)
+		for _, r := range recurrence(layers, l) {
+			upstream = append(upstream, delays[r]...)
+			lanes = append(lanes, delaylanes[r]...)
+		}
+		var neurons []SignalPeripherals
+		var downstream [][][]chan Signal
+		var probes [][]Probe
+		var splices [][]*splice
+		var gates, taps [][]chan Signal
+		var statistics Statistics
+		var table Table
+		if layer.Embedding.Dimensions > 0 {
+			neurons, table = NewEmbeddingLayer(layer, learnrate, upstream[:width - 1], lanes, weights[l], network.faults, network.cancelchan)
+			downstream, gates = make([][][]chan Signal, len(neurons)), make([][]chan Signal, len(neurons))
+		} else if layer.Normalization.Kind != "" {
+			neurons, probes, statistics = NewNormalizationLayer(layer, learnrate, upstream, lanes, weights[l], network.cancelchan)
+			downstream, gates = make([][][]chan Signal, len(neurons)), make([][]chan Signal, len(neurons))
+		} else if layer.Cell.Name == "" {
+			var grouped [][]chan Signal
+			if shared(layers, l) {
+				neurons, grouped = NewSharedLayer(layer, upstream, lanes, connections(inputs, layers, l), parameters, fixed, network.cancelchan)
+				probes = probing(parameters[l])
+			} else if plastic(layers, l) != nil {
+				neurons, grouped, probes, taps = NewPlasticLayer(layer, learnrate, upstream, lanes, weights[l], network.cancelchan)
+			} else {
+				neurons, grouped, splices = network.dense(layer, upstream, lanes, weights[l])
+				probes = probed(splices)
+			}
+			gates = make([][]chan Signal, len(neurons))
+			for _, group := range grouped {
+				downstream = append(downstream, [][]chan Signal {group})
+			}
+		} else {
+			neurons, gates, downstream, probes = NewCellLayer(layer, upstream, lanes, weights[l], spans(inputs, layers, l), network.cancelchan)
+		}
+		network.probes = append(network.probes, probes)
+		network.splices = append(network.splices, splices)
+		network.statistics = append(network.statistics, statistics)
+		network.tables = append(network.tables, table)
+		offset := width
+		for _, r := range recurrence(layers, l) {
+			offset = offset + copy(delaylanes[r], lanes[offset:])
+		}
+		nodes := make([]*junction, width - 1)
+		for i, node := range upstream[:width] {
+			junction := &junction {node: node, lanes: lanes[i], fanout: NewFan(), cancelchan: network.cancelchan}
+			if l > 0 && i < width - 1 && network.splices[l - 1] != nil {junction.cancelchan = network.branch()}
+			if len(junction.lanes) == 0 {network.ground(junction)}
+			if l == 0 || i == width - 1 {
+				Source(node, junction.lanes, junction.fanout, network.faults, junction.cancelchan)
+			} else {
+				network.echo(network.delays[l - 1], i, junction)
+				junction.lanes = tap(junction.lanes, tapped[i], junction.cancelchan)
+				network.spawn(learnrate, layers[l - 1], junction, terminals[i], channels[i])
+			}
+			if i == width - 1 {
+				network.biases = append(network.biases, junction)
+			} else {
+				nodes[i] = junction
+			}
+		}
+		network.nodes = append(network.nodes, nodes)
+		if taps == nil {taps = make([][]chan Signal, len(neurons))}
+		upstream, terminals, channels, tapped = neurons, downstream, gates, taps
+	}
+	nodes := make([]*junction, len(upstream))
+	for j, neuron := range upstream {
+		nodes[j] = &junction {node: neuron, lanes: []chan Signal {neuron.Output}, fanout: NewFan(), cancelchan: network.cancelchan}
+		if network.splices[len(layers) - 1] != nil {nodes[j].cancelchan = network.branch()}
+		network.echo(network.delays[len(layers) - 1], j, nodes[j])
+		nodes[j].lanes = tap(nodes[j].lanes, tapped[j], nodes[j].cancelchan)
+		network.spawn(learnrate, layers[len(layers) - 1], nodes[j], terminals[j], channels[j])
+	}
+	network.nodes = append(network.nodes, nodes)
+	for r := range network.delays {
+		for k, delay := range network.delays[r] {
+			delay.lanes = delaylanes[r][k]
+			Source(delay.node, delay.lanes, delay.fanout, network.faults, delay.cancelchan)
+		}
+	}
+	network.outputs = upstream
+}
+
+func Transpose (rows [][]float64, width int) []Signal {
+	signals := make([]Signal, width)
+	for i := range signals {
+		signals[i] = NewSignal(len(rows))
+		for b, row := range rows {
+			signals[i].Values[b] = row[i]
+		}
+	}
+	return signals
+}
+
+func Rows (signals []Signal) [][]float64 {
+	if len(signals) == 0 {return nil}
+	rows := make([][]float64, len(signals[0].Values))
+	for b := range rows {
+		rows[b] = make([]float64, len(signals))
+		for i, signal := range signals {
+			rows[b][i] = signal.Values[b]
+		}
+	}
+	return rows
+}
+
+func (network *Network) feed (inputs [][]float64, tick Tick) {
+	tick.Width = len(inputs)
+	for _, clock := range network.clocks {
+		clock <- tick
+	}
+	signals := Transpose(inputs, network.Inputs)
+	for i, source := range network.sources {
+		signal := Fill(len(inputs), 1)
+		if i < network.Inputs {signal = signals[i]}
+		signal.Sample, signal.Step, signal.Infer = tick.Sample, tick.Step, tick.Infer
+		source.Input <- signal
+	}
+}
+
+func (network *Network) collect () (int, [][]float64) {
+	outputs := make([]Signal, len(network.outputs))
+	for j, neuron := range network.outputs {
+		outputs[j] = <- neuron.Output
+	}
+	return outputs[0].Sample, Rows(outputs)
+}
+
+func (network *Network) ForwardBatch (inputs [][]float64) [][]float64 {
+	network.release()
+	network.sample++
+	network.feed(inputs, Tick {Sample: network.sample, Start: true, Reset: true})
+	_, outputs := network.collect()
+	network.pending = len(inputs)
+	return outputs
+}
+
+func (network *Network) BackwardBatch (errormargins [][]float64) [][]float64 {
+	network.pending = 0
+	return network.backward(errormargins, 0, true, false)
+}
+
+func (network *Network) release () {
+	if network.pending == 0 {return}
+	errormargins := make([][]float64, network.pending)
+	for s := range errormargins {
+		errormargins[s] = make([]float64, len(network.outputs))
+	}
+	network.pending = 0
+	network.backward(errormargins, 0, true, true)
+}
+
+func (network *Network) backward (errormargins [][]float64, step int, last bool, infer bool) [][]float64 {
+	if last {
+		for _, clock := range network.clocks {
+			clock <- Tick {Sample: network.sample, Step: step, Width: len(errormargins), Infer: infer, Backward: true}
+		}
+	}
+	for j, signal := range Transpose(errormargins, len(network.outputs)) {
+		signal.Sample, signal.Step, signal.Infer = network.sample, step, infer
+		network.outputs[j].Upfeed <- signal
+	}
+	feedback := make([]Signal, len(network.sources))
+	for i, source := range network.sources {
+		feedback[i] = <- source.Downfeed
+	}
+	return Rows(feedback[:network.Inputs])
+}
+
+func (network *Network) Forward (input []float64) []float64 {
+	return network.ForwardBatch([][]float64 {input})[0]
+}
+
+func (network *Network) Backward (errormargins []float64) []float64 {
+	feedback := network.BackwardBatch([][]float64 {errormargins})
+	if len(feedback) == 0 {return nil}
+	return feedback[0]
+}
+
+func (network *Network) Weights () [][][]float64 {
+	weights := make([][][]float64, len(network.probes))
+	for l := range network.probes {
+		if network.tables[l].Read != nil {
+			weights[l] = copyWeights([][][]float64 {<- network.tables[l].Read})[0]
+			continue
+		}
+		weights[l] = make([][]float64, len(network.probes[l]))
+		for j := range network.probes[l] {
+			weights[l][j] = make([]float64, len(network.probes[l][j]))
+			for i, probe := range network.probes[l][j] {
+				if probe.Read == nil {continue}
+				weights[l][j][i] = <- probe.Read
+			}
+		}
+	}
+	return weights
+}
+
+func (network *Network) SetWeights (weights [][][]float64) {
+	for l := range network.probes {
+		if network.tables[l].Write != nil {
+			network.tables[l].Write <- weights[l]
+			continue
+		}
+		for j := range network.probes[l] {
+			for i, probe := range network.probes[l][j] {
+				if probe.Write == nil {continue}
+				probe.Write <- weights[l][j][i]
+			}
+		}
+	}
+}
+
+func (network *Network) LearnBatch (sets []TrainingSet) [][]float64 {
+	inputs := make([][]float64, len(sets))
+	for s, set := range sets {
+		inputs[s] = network.Pipeline.Transform(set.Input)
+	}
+	outputs := network.ForwardBatch(inputs)
+	errormargins := make([][]float64, len(sets))
+	for s, output := range outputs {
+		errormargins[s] = make([]float64, len(output))
+		for j := range output {
+			errormargins[s][j] = sets[s].Expect[j] - output[j]
+		}
+	}
+	network.BackwardBatch(errormargins)
+	return outputs
+}
+
+func (network *Network) PredictBatch (inputs [][]float64) [][]float64 {
+	transformed := make([][]float64, len(inputs))
+	for s, input := range inputs {
+		transformed[s] = network.Pipeline.Transform(input)
+	}
+	network.feed(transformed, Tick {Infer: true, Start: true, Reset: true})
+	_, outputs := network.collect()
+	return outputs
+}
+
+func (network *Network) Stream (samples chan Sample) chan Prediction {
+	network.release()
+	predictions := make(chan Prediction)
+	totalchan := make(chan int, 1)
+	var lock sync.Mutex
+	ids := map[int]int {}
+	go func () {
+		total := 0
+		for sample := range samples {
+			network.sample++
+			lock.Lock(); ids[network.sample] = sample.Id; lock.Unlock()
+			network.feed([][]float64 {network.Pipeline.Transform(sample.Input)}, Tick {Sample: network.sample, Infer: true, Start: true, Reset: true})
+			total++
+		}
+		totalchan <- total
+	}()
+	go func () {
+		defer close(predictions)
+		emitted, total := 0, -1
+		for emitted != total {
+			select {
+			case total = <- totalchan:
+				continue
+			case first := <- network.outputs[0].Output:
+				outputs := append([]Signal {first}, make([]Signal, len(network.outputs) - 1)...)
+				for j, neuron := range network.outputs[1:] {
+					outputs[j + 1] = <- neuron.Output
+				}
+				lock.Lock(); id := ids[first.Sample]; delete(ids, first.Sample); lock.Unlock()
+				prediction := Prediction {Id: id, Output: Rows(outputs)[0]}
+				emitted++
+				select {
+				case predictions <- prediction:
+				case <- network.cancelchan:
+					return
+				}
+			case <- network.cancelchan:
+				return
+			}
+		}
+	}()
+	return predictions
+}
+
+func (network *Network) Learn (set TrainingSet) []float64 {
+	return network.LearnBatch([]TrainingSet {set})[0]
+}
+
+func (network *Network) Predict (input []float64) []float64 {
+	return network.PredictBatch([][]float64 {input})[0]
+}
+
+func (network *Network) Train (regimen Regimen, epochs int) {
+	network.Feed(NewSequentialSource(regimen), epochs)
+}
+
+func (network *Network) TrainBatches (regimen Regimen, epochs int, size int) {
+	network.FeedBatches(NewSequentialSource(regimen), epochs, size)
+}
+
+func (network *Network) Feed (source DataSource, epochs int) error {
+	return network.FeedBatches(source, epochs, 1)
+}
+
+func (network *Network) FeedBatches (source DataSource, epochs int, size int) error {
+	for epoch := 0; epoch < epochs; epoch++ {
+		if epoch > 0 {
+			if err := source.Reset(); err != nil {return err}
+		}
+		for batch := Take(source, size); len(batch) > 0; batch = Take(source, size) {
+			network.LearnBatch(batch)
+		}
+		if err := failure(source); err != nil {return err}
+	}
+	return nil
+}
+
+func Take (source DataSource, size int) []TrainingSet {
+	var batch []TrainingSet
+	for len(batch) < size {
+		set, ok := source.Next()
+		if !ok {break}
+		batch = append(batch, set)
+	}
+	return batch
+}
 
-}
\ No newline at end of file
+func (network *Network) Loss (regimen Regimen) float64 {
+	var loss float64
+	for _, set := range regimen.TrainingSets {
+		loss = loss + SquaredError(set.Expect, network.Predict(set.Input))
+	}
+	return loss / float64(len(regimen.TrainingSets))
+}
+
+func (network *Network) Faults () chan Fault {
+	return network.faults
+}
+
+func (network *Network) Close () {
+	close(network.cancelchan)
+	for cancelchan := range network.branches {
+		close(cancelchan)
+	}
+}
+
+func SquaredError (expect []float64, output []float64) float64 {
+	var sum float64
+	for j := range expect {
+		sum = sum + (expect[j] - output[j]) * (expect[j] - output[j])
+	}
+	return sum / 2
+}
@@ -0,0 +1,126 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Network_Stream_Ordering (t *testing.T) {
+	resultchan := make(chan struct{})
+	var timeout time.Duration = 500
+	layers := []Layer {{Neurons: 4, Activation: Activations["sigmoid"]}, {Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 31)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	samples := make(chan Sample)
+
+	go func() {
+		predictions := network.Stream(samples)
+		go func() {
+			for id := 0; id < 100; id++ {
+				samples <- Sample {Id: 1000 + id, Input: []float64 {float64(id) / 100, 1 - float64(id) / 100}}
+			}
+			close(samples)
+		}()
+		id := 0
+		for prediction := range predictions {
+			expect := matrix.Predict([]float64 {float64(id) / 100, 1 - float64(id) / 100})
+			if prediction.Id != 1000 + id || math.Abs(prediction.Output[0] - expect[0]) > 1e-12 || math.Abs(prediction.Output[1] - expect[1]) > 1e-12 {
+				t.Log("Failure - Streamed prediction out of order or inaccurate")
+				t.Log(prediction)
+				return
+			}
+			id++
+		}
+		if id != 100 {
+			t.Log("Failure - Stream lost predictions")
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network stream timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Network stream preserves sample order")
+		return
+	}
+}
+
+func Test_Network_Stream_RepeatedIds (t *testing.T) {
+	resultchan := make(chan struct{})
+	var timeout time.Duration = 3000
+	layers := []Layer {{Neurons: 4, Activation: Activations["sigmoid"]}, {Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 17)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	samples := make(chan Sample)
+
+	go func() {
+		predictions := network.Stream(samples)
+		go func() {
+			for id := 0; id < 200; id++ {
+				sample := Sample {Input: []float64 {float64(id) / 200, 1 - float64(id) / 200}}
+				if id >= 100 {sample.Id = id % 3}
+				samples <- sample
+			}
+			close(samples)
+		}()
+		id := 0
+		for prediction := range predictions {
+			expect := matrix.Predict([]float64 {float64(id) / 200, 1 - float64(id) / 200})
+			caller := 0
+			if id >= 100 {caller = id % 3}
+			if prediction.Id != caller || math.Abs(prediction.Output[0] - expect[0]) > 1e-12 || math.Abs(prediction.Output[1] - expect[1]) > 1e-12 {
+				t.Log("Failure - Streamed prediction with a shared id is inaccurate")
+				t.Log(prediction)
+				return
+			}
+			id++
+		}
+		if id != 200 {
+			t.Log("Failure - Stream dropped samples that share an id")
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network stream with shared ids timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Network stream keeps samples that share an id")
+		return
+	}
+}
+
+func Test_Network_Predict_Inference (t *testing.T) {
+	resultchan := make(chan struct{})
+	var timeout time.Duration = 50
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0.5, 1, layers, RandomWeights(1, layers, 3))
+
+	go func() {
+		network.Predict([]float64 {1}); network.Predict([]float64 {0})
+		network.Learn(TrainingSet {Input: []float64 {1}, Expect: []float64 {0}})
+		network.Predict([]float64 {1})
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network inference without feedback timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Network inference needs no feedback")
+		return
+	}
+}
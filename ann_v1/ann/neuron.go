@@ -2,17 +2,18 @@ package ann
 
 import (
 	"math"
-	"time"
-	"fmt"
 )
 
-const devneuron bool = false
-const devsynapse bool = false
-
 var Sigmoid func(float64)float64 = func (x float64) float64 {return 1/(1+math.Exp(-x))}
 var SigmoidDerivative func(float64)float64 = func (x float64) float64 {return 1/(1+math.Exp(-x))*(1-1/(1+math.Exp(-x)))}
 
+var Activations = map[string]Activation {
+	"sigmoid": {Name: "sigmoid", Function: Sigmoid, Derivative: SigmoidDerivative},
+	"identity": {Name: "identity", Function: func (x float64) float64 {return x}, Derivative: func (x float64) float64 {return 1}},
+}
+
 type Activation struct {
+	Name string
 	Function func(float64)float64
 	Derivative func(float64)float64
 }
@@ -29,6 +30,11 @@ type Peripherals struct {
 	Downfeed chan float64
 }
 
+type Probe struct {
+	Read chan float64
+	Write chan float64
+}
+
 
 func PushOrCancel (value float64, outchan chan float64, cancelchan chan struct{}) bool {
 	select {
@@ -48,6 +54,15 @@ func PullOrCancel (inchan chan float64, cancelchan chan struct{}) (bool, float64
 	}
 }
 
+func relay (signal Signal, inchan chan Signal, outchan chan Signal, replies int, scalarchan chan float64, cancelchan chan struct{}) bool {
+	if !PushSignalOrCancel(signal, inchan, cancelchan) {return false}
+	for r := 0; r < replies; r++ {
+		ok, reply := PullSignalOrCancel(outchan, cancelchan)
+		if !ok || !PushOrCancel(reply.Values[0], scalarchan, cancelchan) {return false}
+	}
+	return true
+}
+
 func NewNeuron (learnrate float64, synapses Synapses, activation Activation, peripherals Peripherals, cancelchan chan struct{}) {
 	internals := Peripherals {
 		Input: make(chan float64),
@@ -61,25 +76,17 @@ func NewNeuron (learnrate float64, synapses Synapses, activation Activation, per
 	go Dendrite (synapses.Ingoing, peripherals.Input, internals.Input, cancelchan)
 	go Axon (synapses.Ingoing, internals.Downfeed, peripherals.Downfeed, cancelchan)
 	go Dendrite (synapses.Outgoing, peripherals.Upfeed, internals.Upfeed, cancelchan)
-	if devneuron {fmt.Printf("\n%v: Neuron initialized...\n", time.Now())}
 }
 
 func Nucleus (learnrate float64, activation Activation, peripherals Peripherals, cancelchan chan struct{}) {
-	var excitement float64
+	internals := NewSignalPeripherals()
+	go SignalNucleus (learnrate, activation, internals, cancelchan)
 	for {
 		select {
 		case input := <- peripherals.Input:
-			if devneuron {fmt.Printf("\n%v: Nucleus received [%f]...\n", time.Now(), input)}
-			excitement = input
-			if devneuron {fmt.Printf("\n%v: Nucleus excitement [%f]...\n", time.Now(), activation.Function(excitement))}
-			peripherals.Output <- activation.Function(excitement)
-			if devneuron {fmt.Printf("\n%v: Nucleus output [%f]...\n", time.Now(), activation.Function(excitement))}
+			if !relay(Scalar(input), internals.Input, internals.Output, 1, peripherals.Output, cancelchan) {return}
 		case errormargin := <- peripherals.Upfeed:
-			if devneuron {fmt.Printf("\n%v: Nucleus upfed [%f]...\n", time.Now(), errormargin)}
-			peripherals.Downfeed <- errormargin
-			if devneuron {fmt.Printf("\n%v: Nucleus downfed [%f]...\n", time.Now(), errormargin)}
-			peripherals.Downfeed <- learnrate * errormargin * activation.Derivative(excitement)
-			if devneuron {fmt.Printf("\n%v: Nucleus received [%f]...\n", time.Now(), learnrate * errormargin * activation.Derivative(excitement))}
+			if !relay(Scalar(errormargin), internals.Upfeed, internals.Downfeed, 2, peripherals.Downfeed, cancelchan) {return}
 		case <- cancelchan:
 			return
 		}
@@ -87,35 +94,29 @@ func Nucleus (learnrate float64, activation Activation, peripherals Peripherals,
 }
 
 func Dendrite (signals int, inputchan chan float64, outputchan chan float64, cancelchan chan struct{}) {
+	internals := NewSignalPeripherals()
+	go SignalDendrite (signals, internals.Input, internals.Output, cancelchan)
 	var signalcount int
-	var sum float64
 	for {
 		select {
 		case input := <- inputchan:
-			if devneuron {fmt.Printf("\n%v: Dendrite received signal [%f]...\n", time.Now(), input)}
 			signalcount++
-			sum = sum + input
-			if signalcount == signals {
-				if devneuron {fmt.Printf("\n%v: Dendrite relayed signal [%f]...\n", time.Now(), sum)}
-				outputchan <- sum
-				sum = 0
-				signalcount = 0
-			}
+			replies := 0
+			if signalcount == signals {replies, signalcount = 1, 0}
+			if !relay(Scalar(input), internals.Input, internals.Output, replies, outputchan, cancelchan) {return}
 		case <- cancelchan:
 			return
 		}
 	}
 }
 
-
 func Axon (signals int, inputchan chan float64, outputchan chan float64, cancelchan chan struct{}) {
+	internals := NewSignalPeripherals()
+	go SignalAxon (signals, internals.Input, internals.Output, cancelchan)
 	for {
 		select {
 		case input := <- inputchan:
-			for i := 0; i < signals; i++ {
-				outputchan <- input
-				if devneuron {fmt.Printf("\n%v: Axon relayed signal [%f]...\n", time.Now(), input)}
-			}
+			if !relay(Scalar(input), internals.Input, internals.Output, signals, outputchan, cancelchan) {return}
 		case <- cancelchan:
 			return
 		}
@@ -123,26 +124,21 @@ func Axon (signals int, inputchan chan float64, outputchan chan float64, cancelc
 }
 
 func Synapse (weight float64, peripherals Peripherals, cancelchan chan struct{}) {
-	var output float64
+	internals := NewSignalPeripherals()
+	go SignalSynapse (weight, internals, Probe {}, cancelchan)
 	var inputchan chan float64 = peripherals.Input
 	for {
 		select {
 		case input := <- inputchan:
-			if devsynapse {fmt.Printf("\n%v: Synapse received input [%f]...\n", time.Now(), input)}
-			output = input * weight
-			peripherals.Output <- output
-			if devsynapse {fmt.Printf("\n%v: Synapse relayed signal [%f]...\n", time.Now(), output)}
+			if !relay(Scalar(input), internals.Input, internals.Output, 1, peripherals.Output, cancelchan) {return}
 			inputchan = nil
 		case errormargin := <- peripherals.Upfeed:
-			if devsynapse {fmt.Printf("\n%v: Synapse received error margin [%f]...\n", time.Now(), errormargin)}
-			peripherals.Downfeed <- errormargin * weight
-			if devsynapse {fmt.Printf("\n%v: Synapse relayed margin [%f]...\n", time.Now(), errormargin * weight)}
-			adjustment := <- peripherals.Upfeed
-			if devsynapse {fmt.Printf("\n%v: Synapse received adjustment [%f]...\n", time.Now(), adjustment)}
-			weight = weight + (adjustment * output)
+			if !relay(Scalar(errormargin), internals.Upfeed, internals.Downfeed, 1, peripherals.Downfeed, cancelchan) {return}
+			ok, adjustment := PullOrCancel(peripherals.Upfeed, cancelchan)
+			if !ok || !PushSignalOrCancel(Scalar(adjustment), internals.Upfeed, cancelchan) {return}
 			inputchan = peripherals.Input
 		case <- cancelchan:
 			return
 		}
 	}
-}
\ No newline at end of file
+}
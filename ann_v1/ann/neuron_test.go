@@ -320,7 +320,7 @@ func Test_Nucleus_Feedback_ErrorMargin (t *testing.T) {
 	go func() {
 		internals.Upfeed <- 0.5
 		result := <- internals.Downfeed
-		if result != 0.5 {
+		if result != 0.5 * SigmoidDerivative(0) {
 			t.Log("Failure -  Nucleus margin error is inaccurate")
 			t.Log(result)
 			return
@@ -802,7 +802,7 @@ func Test_Neuron_Feedback_ErrorMargin (t *testing.T) {
 	NewNeuron(learningrate, synapses, activation, neuron, cancelchan)
 	go func() {
 		neuron.Upfeed <- 0.5; result := <- neuron.Downfeed
-		if result != 0.5 {
+		if result != 0.5 * SigmoidDerivative(0) {
 			t.Log("Failure - Neuron feedback error margin inaccurate")
 			t.Log(result)
 			return
@@ -876,12 +876,12 @@ func Test_Neuron_Loop_Workflow (t *testing.T) {
 	}
 }
 
-func Test_Neuron_Loop_Workflow (t *testing.T) {
+func Test_Neuron_Single_Loop_Workflow (t *testing.T) {
 	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
 	var timeout time.Duration = 10
 	activation := Activation {Function: Sigmoid, Derivative: SigmoidDerivative}
 	neuron := Peripherals {Input: make(chan float64), Output: make(chan float64), Upfeed: make(chan float64), Downfeed: make(chan float64)}
-	synapses := Synapses {Ingoing: 1, Outgoing: 1}; learningrate := 1
+	synapses := Synapses {Ingoing: 1, Outgoing: 1}; learningrate := 1.0
 
 	NewNeuron(learningrate, synapses, activation, neuron, cancelchan)
 	go func() {
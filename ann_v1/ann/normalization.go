@@ -0,0 +1,400 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const devnormalization bool = false
+
+type Normalization struct {
+	Kind string
+	Momentum float64
+	Epsilon float64
+}
+
+type Statistics struct {
+	Read chan [][]float64
+	Write chan [][]float64
+}
+
+type normalized struct {
+	values [][]float64
+	deviations [][]float64
+}
+
+func BatchNormalization (neurons int) Layer {
+	return Layer {Neurons: neurons, Activation: Activations["identity"], Normalization: Normalization {Kind: "batch"}}
+}
+
+func LayerNormalization (neurons int) Layer {
+	return Layer {Neurons: neurons, Activation: Activations["identity"], Normalization: Normalization {Kind: "layer"}}
+}
+
+func CheckNormalizations (inputs int, layers []Layer) error {
+	for l, layer := range layers {
+		kind := layer.Normalization.Kind
+		if kind == "" {continue}
+		if kind != "batch" && kind != "layer" {return fmt.Errorf("ann: layer %d has unknown normalization %q", l, kind)}
+		fanin := inputs
+		if l > 0 {fanin = layers[l - 1].Neurons}
+		if layer.Neurons != fanin {
+			return fmt.Errorf("ann: layer %d normalizes %d neurons but has %d inputs", l, layer.Neurons, fanin)
+		}
+	}
+	return nil
+}
+
+func (normalization Normalization) momentum () float64 {
+	if normalization.Momentum <= 0 {return 0.9}
+	return normalization.Momentum
+}
+
+func (normalization Normalization) epsilon () float64 {
+	if normalization.Epsilon <= 0 {return 1e-5}
+	return normalization.Epsilon
+}
+
+func Normalize (values []float64, epsilon float64) ([]float64, float64, float64) {
+	var mean, variance float64
+	for _, value := range values {
+		mean = mean + value
+	}
+	mean = mean / float64(len(values))
+	for _, value := range values {
+		variance = variance + (value - mean) * (value - mean)
+	}
+	variance = variance / float64(len(values))
+	deviation := math.Sqrt(variance + epsilon)
+	normalized := make([]float64, len(values))
+	for i, value := range values {
+		normalized[i] = (value - mean) / deviation
+	}
+	return normalized, mean, variance
+}
+
+func Denormalize (normalized []float64, deviation float64, margins []float64) []float64 {
+	var sum, dot float64
+	for i, margin := range margins {
+		sum, dot = sum + margin, dot + margin * normalized[i]
+	}
+	count := float64(len(margins))
+	upstream := make([]float64, len(margins))
+	for i, margin := range margins {
+		upstream[i] = (count * margin - sum - normalized[i] * dot) / (count * deviation)
+	}
+	return upstream
+}
+
+func normalizationWeights (layer Layer) [][]float64 {
+	weights := make([][]float64, layer.Neurons)
+	for j := range weights {
+		weights[j] = []float64 {1, 0}
+	}
+	return weights
+}
+
+func initialStatistics (layers []Layer) [][][]float64 {
+	statistics := make([][][]float64, len(layers))
+	for l, layer := range layers {
+		if layer.Normalization.Kind != "batch" {continue}
+		statistics[l] = make([][]float64, layer.Neurons)
+		for j := range statistics[l] {
+			statistics[l][j] = []float64 {0, 1}
+		}
+	}
+	return statistics
+}
+
+func copyStatistics (statistics [][][]float64) [][][]float64 {
+	copied := make([][][]float64, len(statistics))
+	for l := range statistics {
+		if statistics[l] == nil {continue}
+		copied[l] = make([][]float64, len(statistics[l]))
+		for j := range statistics[l] {
+			copied[l][j] = append([]float64 {}, statistics[l][j]...)
+		}
+	}
+	return copied
+}
+
+func Label (source int, inputchan chan Signal, outputchan chan Signal, cancelchan chan struct{}) {
+	for {
+		ok, signal := PullSignalOrCancel(inputchan, cancelchan)
+		if !ok {return}
+		signal.Source = source
+		if !PushSignalOrCancel(signal, outputchan, cancelchan) {return}
+	}
+}
+
+func gather (arrivals map[moment][]Signal, signal Signal, width int) ([]Signal, bool) {
+	arrived, ok := arrivals[signal.moment()]
+	if !ok {
+		arrived = make([]Signal, width)
+		arrivals[signal.moment()] = arrived
+	}
+	arrived[signal.Source] = signal
+	for _, other := range arrived {
+		if other.Values == nil {return nil, false}
+	}
+	delete(arrivals, signal.moment())
+	return arrived, true
+}
+
+func Normalizer (normalization Normalization, learnrate float64, parameters [][]Parameter, statistics Statistics, inputchan chan Signal, marginchan chan Signal, outputchans []chan Signal, downfeeds []chan Signal, targets []int, cancelchan chan struct{}) {
+	width := len(outputchans)
+	inputs, margins := map[moment][]Signal {}, map[moment][]Signal {}
+	records := map[moment]normalized {}
+	running := make([][]float64, width)
+	for j := range running {
+		running[j] = []float64 {0, 1}
+	}
+	snapshot := copyStatistics([][][]float64 {running})[0]
+	weights := make([][]float64, width)
+	pending := make([][]float64, width)
+	for j := range weights {
+		weights[j], pending[j] = make([]float64, 2), make([]float64, 2)
+	}
+	for {
+		select {
+		case input := <- inputchan:
+			arrived, ok := gather(inputs, input, width)
+			if !ok {continue}
+			if len(records) == 0 {
+				for j := range parameters {
+					for i, parameter := range parameters[j] {
+						read := parameter.Checkout
+						if input.Infer {read = parameter.Read}
+						if ok, weights[j][i] = PullOrCancel(read, cancelchan); !ok {return}
+					}
+				}
+			}
+			record := normalized {values: make([][]float64, width), deviations: make([][]float64, width)}
+			for j := range record.values {
+				record.values[j] = make([]float64, len(input.Values))
+				record.deviations[j] = make([]float64, len(input.Values))
+			}
+			if normalization.Kind == "batch" {
+				column := make([]float64, len(input.Values))
+				for j, signal := range arrived {
+					copy(column, signal.Values)
+					mean, variance := running[j][0], running[j][1]
+					fixed := input.Infer || len(column) < 2
+					if !fixed {
+						record.values[j], mean, variance = Normalize(column, normalization.epsilon())
+						momentum := normalization.momentum()
+						running[j][0] = momentum * running[j][0] + (1 - momentum) * mean
+						running[j][1] = momentum * running[j][1] + (1 - momentum) * variance
+					}
+					deviation := math.Sqrt(variance + normalization.epsilon())
+					for b, value := range column {
+						if fixed {record.values[j][b] = (value - mean) / deviation}
+						record.deviations[j][b] = deviation
+					}
+				}
+			} else {
+				row := make([]float64, width)
+				for b := range input.Values {
+					for j, signal := range arrived {
+						row[j] = signal.Values[b]
+					}
+					values, _, variance := Normalize(row, normalization.epsilon())
+					for j, value := range values {
+						record.values[j][b], record.deviations[j][b] = value, math.Sqrt(variance + normalization.epsilon())
+					}
+				}
+			}
+			if !input.Infer {
+				records[input.moment()] = record
+				if normalization.Kind == "batch" {snapshot = copyStatistics([][][]float64 {running})[0]}
+			}
+			for j, outputchan := range outputchans {
+				output := Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(input.Values))}
+				for b, value := range record.values[j] {
+					output.Values[b] = weights[j][0] * value + weights[j][1]
+				}
+				if !PushSignalOrCancel(output, outputchan, cancelchan) {return}
+			}
+			if devnormalization {fmt.Printf("\n%v: Normalizer relayed sample %d...\n", time.Now(), input.Sample)}
+		case margin := <- marginchan:
+			arrived, ok := gather(margins, margin, width)
+			if !ok {continue}
+			record := records[margin.moment()]
+			delete(records, margin.moment())
+			upstream := make([][]float64, width)
+			for j, signal := range arrived {
+				upstream[j] = make([]float64, len(signal.Values))
+				for b, value := range signal.Values {
+					pending[j][0] = pending[j][0] + learnrate * value * record.values[j][b]
+					pending[j][1] = pending[j][1] + learnrate * value
+					upstream[j][b] = value * weights[j][0]
+				}
+			}
+			if normalization.Kind == "batch" {
+				for j := range upstream {
+					if len(upstream[j]) > 1 {
+						upstream[j] = Denormalize(record.values[j], record.deviations[j][0], upstream[j])
+						continue
+					}
+					for b := range upstream[j] {
+						upstream[j][b] = upstream[j][b] / record.deviations[j][b]
+					}
+				}
+			} else {
+				row, values := make([]float64, width), make([]float64, width)
+				for b := range upstream[0] {
+					for j := range upstream {
+						row[j], values[j] = upstream[j][b], record.values[j][b]
+					}
+					for j, value := range Denormalize(values, record.deviations[0][b], row) {
+						upstream[j][b] = value
+					}
+				}
+			}
+			for j, downfeed := range downfeeds {
+				if !PushSignalOrCancel(Signal {Sample: margin.Sample, Step: margin.Step, Source: targets[j], Infer: margin.Infer, Values: upstream[j]}, downfeed, cancelchan) {return}
+			}
+			if len(records) > 0 {continue}
+			for j := range parameters {
+				for i, parameter := range parameters[j] {
+					if !PushOrCancel(pending[j][i], parameter.Contribute, cancelchan) {return}
+					pending[j][i] = 0
+				}
+			}
+		case statistics.Read <- snapshot:
+		case written := <- statistics.Write:
+			for j := range running {
+				if j < len(written) {copy(running[j], written[j])}
+			}
+			snapshot = copyStatistics([][][]float64 {running})[0]
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewNormalizationLayer (layer Layer, learnrate float64, upstream []SignalPeripherals, lanes [][]chan Signal, weights [][]float64, cancelchan chan struct{}) ([]SignalPeripherals, [][]Probe, Statistics) {
+	inputchan, marginchan := make(chan Signal), make(chan Signal)
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	outputchans := make([]chan Signal, layer.Neurons)
+	downfeeds := make([]chan Signal, layer.Neurons)
+	targets := make([]int, layer.Neurons)
+	parameters := make([][]Parameter, layer.Neurons)
+	for j := range neurons {
+		lane := make(chan Signal)
+		lanes[j] = append(lanes[j], lane)
+		targets[j], downfeeds[j] = len(lanes[j]) - 1, upstream[j].Upfeed
+		go Label (j, lane, inputchan, cancelchan)
+		neurons[j] = NewSignalPeripherals()
+		outputchans[j] = neurons[j].Input
+		go Label (j, neurons[j].Downfeed, marginchan, cancelchan)
+		parameters[j] = make([]Parameter, len(weights[j]))
+		for i, weight := range weights[j] {
+			parameters[j][i] = NewParameter()
+			go SharedWeight (weight, 1, parameters[j][i], cancelchan)
+		}
+	}
+	statistics := Statistics {Read: make(chan [][]float64), Write: make(chan [][]float64)}
+	go Normalizer (layer.Normalization, learnrate, parameters, statistics, inputchan, marginchan, outputchans, downfeeds, targets, cancelchan)
+	return neurons, probing(parameters), statistics
+}
+
+func (network *Network) Statistics () [][][]float64 {
+	statistics := make([][][]float64, len(network.statistics))
+	for l, channels := range network.statistics {
+		if network.Layers[l].Normalization.Kind == "batch" {statistics[l] = <- channels.Read}
+	}
+	return statistics
+}
+
+func (network *Network) SetStatistics (statistics [][][]float64) {
+	for l, channels := range network.statistics {
+		if network.Layers[l].Normalization.Kind == "batch" && l < len(statistics) && statistics[l] != nil {channels.Write <- statistics[l]}
+	}
+}
+
+func (matrix *Matrix) normalize (l int, signals [][][]float64, excitements [][][]float64, infer bool) [][]float64 {
+	layer := matrix.Layers[l]
+	normalization := layer.Normalization
+	epsilon := normalization.epsilon()
+	outputs := make([][]float64, len(signals))
+	for s := range signals {
+		outputs[s] = make([]float64, layer.Neurons + 1)
+		excitements[s][l] = make([]float64, 2 * layer.Neurons)
+	}
+	if normalization.Kind == "batch" {
+		column := make([]float64, len(signals))
+		for j := 0; j < layer.Neurons; j++ {
+			for s := range signals {
+				column[s] = signals[s][l][j]
+			}
+			running := matrix.Statistics[l][j]
+			mean, variance := running[0], running[1]
+			var values []float64
+			fixed := infer || len(column) < 2
+			if !fixed {
+				values, mean, variance = Normalize(column, epsilon)
+				momentum := normalization.momentum()
+				running[0] = momentum * running[0] + (1 - momentum) * mean
+				running[1] = momentum * running[1] + (1 - momentum) * variance
+			}
+			deviation := math.Sqrt(variance + epsilon)
+			for s, value := range column {
+				if fixed {excitements[s][l][j] = (value - mean) / deviation} else {excitements[s][l][j] = values[s]}
+				excitements[s][l][layer.Neurons + j] = deviation
+			}
+		}
+	} else {
+		for s := range signals {
+			values, _, variance := Normalize(signals[s][l][:layer.Neurons], epsilon)
+			copy(excitements[s][l], values)
+			for j := range values {
+				excitements[s][l][layer.Neurons + j] = math.Sqrt(variance + epsilon)
+			}
+		}
+	}
+	for s := range signals {
+		for j := 0; j < layer.Neurons; j++ {
+			outputs[s][j] = matrix.Weights[l][j][0] * excitements[s][l][j] + matrix.Weights[l][j][1]
+		}
+	}
+	return outputs
+}
+
+func (matrix *Matrix) denormalize (l int, signals [][][]float64, excitements [][][]float64, errormargins [][]float64, adjustments [][][]float64) [][]float64 {
+	layer := matrix.Layers[l]
+	upstreams := make([][]float64, len(signals))
+	scaled := make([][]float64, len(signals))
+	for s := range signals {
+		upstreams[s] = make([]float64, len(signals[s][l]))
+		scaled[s] = make([]float64, layer.Neurons)
+	}
+	for j := 0; j < layer.Neurons; j++ {
+		for s := range signals {
+			adjustments[l][j][0] = adjustments[l][j][0] + matrix.LearnRate * errormargins[s][j] * excitements[s][l][j]
+			adjustments[l][j][1] = adjustments[l][j][1] + matrix.LearnRate * errormargins[s][j]
+			scaled[s][j] = errormargins[s][j] * matrix.Weights[l][j][0]
+		}
+	}
+	if layer.Normalization.Kind == "batch" {
+		column, values := make([]float64, len(signals)), make([]float64, len(signals))
+		for j := 0; j < layer.Neurons; j++ {
+			for s := range signals {
+				column[s], values[s] = scaled[s][j], excitements[s][l][j]
+			}
+			if len(signals) < 2 {
+				upstreams[0][j] = column[0] / excitements[0][l][layer.Neurons + j]
+				continue
+			}
+			for s, value := range Denormalize(values, excitements[0][l][layer.Neurons + j], column) {
+				upstreams[s][j] = value
+			}
+		}
+	} else {
+		for s := range signals {
+			copy(upstreams[s], Denormalize(excitements[s][l][:layer.Neurons], excitements[s][l][layer.Neurons], scaled[s]))
+		}
+	}
+	return upstreams
+}
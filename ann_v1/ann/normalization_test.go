@@ -0,0 +1,216 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func normalizationLayers (kind string) []Layer {
+	return []Layer {
+		{Neurons: 3, Activation: Activations["sigmoid"]},
+		{Neurons: 3, Activation: Activations["identity"], Normalization: Normalization {Kind: kind}},
+		{Neurons: 2, Activation: Activations["sigmoid"]},
+	}
+}
+
+var normalizationSets = []TrainingSet {
+	{Input: []float64 {0.2, 0.9}, Expect: []float64 {0, 1}},
+	{Input: []float64 {0.8, 0.1}, Expect: []float64 {1, 0}},
+	{Input: []float64 {0.5, 0.4}, Expect: []float64 {1, 1}},
+	{Input: []float64 {0.1, 0.3}, Expect: []float64 {0, 0}},
+}
+
+func Test_Matrix_Normalization_Gradients (t *testing.T) {
+	for _, kind := range []string {"batch", "layer"} {
+		layers := normalizationLayers(kind)
+		weights := RandomWeights(2, layers, 19)
+		weights[1][0] = []float64 {1.3, 0.2}
+		matrix := NewMatrix(0.1, 2, layers, weights)
+		matrix.LearnBatch(normalizationSets)
+		inputs := make([][]float64, len(normalizationSets))
+		for s, set := range normalizationSets {
+			inputs[s] = set.Input
+		}
+		loss := func (weights [][][]float64) float64 {
+			var sum float64
+			signals, _, _ := NewMatrix(0.1, 2, layers, weights).steps(inputs, nil, nil, false)
+			for s, set := range normalizationSets {
+				output := signals[s][len(signals[s]) - 1]
+				sum = sum + SquaredError(set.Expect, output[:len(output) - 1])
+			}
+			return sum
+		}
+		for l := range weights {
+			for j := range weights[l] {
+				for i, weight := range weights[l][j] {
+					shifted := copyWeights(weights)
+					shifted[l][j][i] = weight + 1e-6
+					upper := loss(shifted)
+					shifted[l][j][i] = weight - 1e-6
+					lower := loss(shifted)
+					numeric := (upper - lower) / 2e-6
+					analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+					if math.Abs(numeric - analytic) > 1e-6 {
+						t.Log("Failure - Normalization gradients disagree with finite differences")
+						t.Log(kind, l, j, i, analytic, numeric)
+						t.Fail()
+						return
+					}
+				}
+			}
+		}
+	}
+	t.Log("Success - Normalization gradients agree with finite differences")
+}
+
+func Test_Network_Normalization_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 1000
+
+	go func() {
+		var divergence float64
+		for _, kind := range []string {"batch", "layer"} {
+			layers := normalizationLayers(kind)
+			weights := RandomWeights(2, layers, 29)
+			network, _ := NewNetwork(0.5, 2, layers, weights)
+			matrix := NewMatrix(0.5, 2, layers, weights)
+			for epoch := 0; epoch < 10; epoch++ {
+				expected := matrix.LearnBatch(normalizationSets)
+				for s, output := range network.LearnBatch(normalizationSets) {
+					for j := range output {
+						divergence = math.Max(divergence, math.Abs(output[j] - expected[s][j]))
+					}
+				}
+			}
+			after := network.Weights()
+			for l := range after {
+				for j := range after[l] {
+					for i := range after[l][j] {
+						divergence = math.Max(divergence, math.Abs(after[l][j][i] - matrix.Weights[l][j][i]))
+					}
+				}
+			}
+			var buffer bytes.Buffer
+			network.Save(&buffer)
+			loaded, err := Load(&buffer)
+			if err != nil {
+				resultchan <- math.Inf(1)
+				return
+			}
+			for _, set := range normalizationSets {
+				expected := matrix.Predict(set.Input)
+				for j, output := range network.Predict(set.Input) {
+					divergence = math.Max(divergence, math.Abs(output - expected[j]))
+				}
+				for j, output := range loaded.Predict(set.Input) {
+					divergence = math.Max(divergence, math.Abs(output - expected[j]))
+				}
+			}
+			network.Close(); loaded.Close()
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Normalization network timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Normalization network diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Normalization network matches the matrix engine and survives a save and load")
+		return
+	}
+}
+
+func Test_Network_Normalization_Width (t *testing.T) {
+	for _, layers := range [][]Layer {
+		{{Neurons: 3, Activation: Activations["sigmoid"]}, BatchNormalization(4), {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{LayerNormalization(3), {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{{Neurons: 3, Activation: Activations["identity"], Normalization: Normalization {Kind: "group"}}},
+	} {
+		if network, err := NewNetwork(0.5, 2, layers, nil); err == nil {
+			network.Close()
+			t.Log("Failure - Network accepted a normalization that does not match its input")
+			t.Fail()
+			return
+		}
+	}
+	if err := CheckNormalizations(2, normalizationLayers("layer")); err != nil {
+		t.Log("Failure - Normalization check rejected a matching layer")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Network rejects normalization layers that do not match their input")
+}
+
+func Test_Network_Normalization_SingleSample (t *testing.T) {
+	resultchan := make(chan []float64)
+	var timeout time.Duration = 1000
+	layers := normalizationLayers("batch")
+	weights := RandomWeights(2, layers, 31)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+
+	go func() {
+		var divergence, spread, relative float64
+		for epoch := 0; epoch < 10; epoch++ {
+			for _, set := range normalizationSets {
+				expected := matrix.Learn(set)
+				for j, output := range network.Learn(set) {
+					divergence = math.Max(divergence, math.Abs(output - expected[j]))
+				}
+			}
+		}
+		first, second := network.Predict(normalizationSets[0].Input), network.Predict(normalizationSets[1].Input)
+		for j := range first {
+			spread = math.Max(spread, math.Abs(first[j] - second[j]))
+		}
+		checks, _ := CheckGradients(network, normalizationSets[2], 1e-5)
+		for _, check := range checks {
+			relative = math.Max(relative, check.RelativeError)
+		}
+		resultchan <- []float64 {divergence, spread, relative}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Single sample normalization timed out")
+		t.Fail()
+		return
+	case result := <- resultchan:
+		if result[0] > 1e-9 || result[1] == 0 || result[2] > 1e-5 {
+			t.Log("Failure - Single sample batch normalization collapsed or diverged")
+			t.Log(result)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Single sample batch normalization falls back to running statistics")
+		return
+	}
+}
+
+func Test_Matrix_Normalization_Running (t *testing.T) {
+	layers := normalizationLayers("batch")
+	matrix := NewMatrix(0.5, 2, layers, RandomWeights(2, layers, 3))
+	for epoch := 0; epoch < 50; epoch++ {
+		matrix.LearnBatch(normalizationSets)
+	}
+	running := matrix.Statistics[1]
+	if len(running) != 3 || running[0][0] == 0 || running[0][1] == 1 || matrix.Statistics[0] != nil {
+		t.Log("Failure - Batch normalization kept no running statistics")
+		t.Log(matrix.Statistics)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Batch normalization tracks running statistics")
+}
@@ -0,0 +1,194 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const devplasticity bool = false
+
+var Rules = map[string]Rule {
+	"hebbian": {Name: "hebbian", Delta: func (weight float64, pre float64, post float64, threshold float64) float64 {return pre * post}},
+	"oja": {Name: "oja", Delta: func (weight float64, pre float64, post float64, threshold float64) float64 {return post * (pre - post * weight)}},
+	"bcm": {Name: "bcm", Delta: func (weight float64, pre float64, post float64, threshold float64) float64 {return pre * post * (post - threshold)}, Averaging: 0.1},
+}
+
+type Rule struct {
+	Name string
+	Delta func (weight float64, pre float64, post float64, threshold float64) float64
+	Averaging float64
+}
+
+func (rule Rule) MarshalJSON () ([]byte, error) {
+	if rule.Name == "" {return json.Marshal("")}
+	if _, ok := Rules[rule.Name]; !ok {return nil, fmt.Errorf("ann: unregistered rule %q", rule.Name)}
+	return json.Marshal(rule.Name)
+}
+
+func (rule *Rule) UnmarshalJSON (data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {return err}
+	if name == "" {
+		*rule = Rule {}
+		return nil
+	}
+	registered, ok := Rules[name]
+	if !ok {return fmt.Errorf("ann: unregistered rule %q", name)}
+	*rule = registered
+	return nil
+}
+
+func (rule Rule) adapt (weight float64, pre float64, post float64, threshold float64) (float64, float64) {
+	return rule.Delta(weight, pre, post, threshold), threshold + rule.Averaging * (post * post - threshold)
+}
+
+func plastic (layers []Layer, l int) [][]Rule {
+	if layers[l].Rules == nil || !plain(layers, l) || linked(layers, l) {return nil}
+	return layers[l].Rules
+}
+
+func PlasticSynapse (learnrate float64, weight float64, rule Rule, tag Tag, peripherals SignalPeripherals, activitychan chan Signal, probe Probe, cancelchan chan struct{}) {
+	inputs := map[moment]Signal {}
+	activities := map[moment]Signal {}
+	var pending, threshold float64
+	for {
+		select {
+		case signal := <- peripherals.Input:
+			output := Signal {Sample: signal.Sample, Step: signal.Step, Source: tag.Source, Infer: signal.Infer, Values: make([]float64, len(signal.Values))}
+			for b, value := range signal.Values {
+				output.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if !signal.Infer {inputs[signal.moment()] = signal}
+		case activity := <- activitychan:
+			if !activity.Infer {activities[activity.moment()] = activity}
+		case errormargin := <- peripherals.Upfeed:
+			margin := Signal {Sample: errormargin.Sample, Step: errormargin.Step, Source: tag.Target, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			for b, value := range errormargin.Values {
+				margin.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(margin, peripherals.Downfeed, cancelchan) {return}
+			if ok, _ := PullSignalOrCancel(peripherals.Upfeed, cancelchan); !ok {return}
+			for _, arrived := activities[errormargin.moment()]; !arrived; _, arrived = activities[errormargin.moment()] {
+				ok, activity := PullSignalOrCancel(activitychan, cancelchan)
+				if !ok {return}
+				if !activity.Infer {activities[activity.moment()] = activity}
+			}
+			input, activity := inputs[errormargin.moment()], activities[errormargin.moment()]
+			delete(inputs, errormargin.moment()); delete(activities, errormargin.moment())
+			for b := 0; b < len(input.Values) && b < len(activity.Values) && !errormargin.Infer; b++ {
+				var delta float64
+				delta, threshold = rule.adapt(weight, input.Values[b], activity.Values[b], threshold)
+				pending = pending + learnrate * delta
+			}
+			if len(inputs) > 0 {continue}
+			weight, pending = weight + pending, 0
+			if devplasticity {fmt.Printf("\n%v: Synapse adapted [%f] by rule %s...\n", time.Now(), weight, rule.Name)}
+		case probe.Read <- weight:
+		case weight = <- probe.Write:
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewPlasticLayer (layer Layer, learnrate float64, upstream []SignalPeripherals, lanes [][]chan Signal, weights [][]float64, cancelchan chan struct{}) ([]SignalPeripherals, [][]chan Signal, [][]Probe, [][]chan Signal) {
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	terminals := make([][]chan Signal, layer.Neurons)
+	probes := make([][]Probe, layer.Neurons)
+	taps := make([][]chan Signal, layer.Neurons)
+	for j := range neurons {
+		neurons[j] = NewSignalPeripherals()
+		terminals[j] = make([]chan Signal, len(upstream))
+		probes[j] = make([]Probe, len(upstream))
+		for i, source := range upstream {
+			lane := make(chan Signal)
+			lanes[i] = append(lanes[i], lane)
+			terminals[j][i] = make(chan Signal)
+			probes[j][i] = Probe {Read: make(chan float64), Write: make(chan float64)}
+			tag := Tag {Source: i, Target: len(lanes[i]) - 1}
+			synapse := SignalPeripherals {Input: lane, Output: neurons[j].Input, Upfeed: terminals[j][i], Downfeed: source.Upfeed}
+			if layer.Rules[j][i].Delta == nil {
+				go TaggedSynapse (weights[j][i], tag, synapse, probes[j][i], cancelchan)
+				continue
+			}
+			activity := make(chan Signal)
+			taps[j] = append(taps[j], activity)
+			go PlasticSynapse (learnrate, weights[j][i], layer.Rules[j][i], tag, synapse, activity, probes[j][i], cancelchan)
+		}
+	}
+	return neurons, terminals, probes, taps
+}
+
+func tap (lanes []chan Signal, taps []chan Signal, cancelchan chan struct{}) []chan Signal {
+	if len(taps) == 0 {return lanes}
+	tee := make(chan Signal)
+	go Terminals (tee, append([]chan Signal {lanes[0]}, taps...), cancelchan)
+	return append([]chan Signal {tee}, lanes[1:]...)
+}
+
+func (matrix *Matrix) plasticity (l int, signal []float64, excitements []float64, rules [][]Rule, adjustments [][][]float64) {
+	if matrix.thresholds == nil {matrix.thresholds = make([][][]float64, len(matrix.Layers))}
+	if matrix.thresholds[l] == nil {matrix.thresholds[l] = zeroWeights(matrix.Weights[l:l + 1])[0]}
+	for j := range rules {
+		post := matrix.Layers[l].Activation.Function(excitements[j])
+		for i, rule := range rules[j] {
+			if rule.Delta == nil {continue}
+			var delta float64
+			delta, matrix.thresholds[l][j][i] = rule.adapt(matrix.Weights[l][j][i], signal[i], post, matrix.thresholds[l][j][i])
+			adjustments[l][j][i] = adjustments[l][j][i] + matrix.LearnRate * delta
+		}
+	}
+}
+
+func (network *Network) AdaptBatch (inputs [][]float64) [][]float64 {
+	transformed := make([][]float64, len(inputs))
+	for s, input := range inputs {
+		transformed[s] = network.Pipeline.Transform(input)
+	}
+	outputs := network.ForwardBatch(transformed)
+	network.BackwardBatch(zeroMargins(outputs))
+	return outputs
+}
+
+func (network *Network) Adapt (regimen Regimen, epochs int) {
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, set := range regimen.TrainingSets {
+			network.AdaptBatch([][]float64 {set.Input})
+		}
+	}
+}
+
+func (matrix *Matrix) AdaptBatch (inputs [][]float64) [][]float64 {
+	adjustments := matrix.adjustments()
+	transformed := make([][]float64, len(inputs))
+	for s, input := range inputs {
+		transformed[s] = matrix.Pipeline.Transform(input)
+	}
+	signals, excitements, _ := matrix.steps(transformed, nil, nil, false)
+	outputs := make([][]float64, len(inputs))
+	for s := range outputs {
+		output := signals[s][len(signals[s]) - 1]
+		outputs[s] = output[:len(output) - 1]
+	}
+	matrix.backsteps(signals, excitements, nil, zeroMargins(outputs), nil, nil, adjustments)
+	matrix.Adjust(adjustments)
+	return outputs
+}
+
+func (matrix *Matrix) Adapt (regimen Regimen, epochs int) {
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, set := range regimen.TrainingSets {
+			matrix.AdaptBatch([][]float64 {set.Input})
+		}
+	}
+}
+
+func zeroMargins (outputs [][]float64) [][]float64 {
+	margins := make([][]float64, len(outputs))
+	for s, output := range outputs {
+		margins[s] = make([]float64, len(output))
+	}
+	return margins
+}
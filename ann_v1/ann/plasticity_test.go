@@ -0,0 +1,116 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func Test_Matrix_Plasticity_Oja (t *testing.T) {
+	random := rand.New(rand.NewSource(21))
+	var regimen Regimen
+	for s := 0; s < 500; s++ {
+		common, noise := random.NormFloat64(), random.NormFloat64() * 0.2
+		regimen.TrainingSets = append(regimen.TrainingSets, TrainingSet {Input: []float64 {common + noise, common - noise}})
+	}
+	layers := []Layer {{Neurons: 1, Activation: Activations["identity"], Rules: [][]Rule {{Rules["oja"], Rules["oja"], {}}}}}
+	matrix := NewMatrix(0.01, 2, layers, [][][]float64 {{{0.3, -0.1, 0}}})
+	matrix.Adapt(regimen, 5)
+	weights := matrix.Weights[0][0]
+	norm := math.Sqrt(weights[0] * weights[0] + weights[1] * weights[1])
+	if math.Abs(norm - 1) > 0.05 || math.Abs(weights[0] - weights[1]) > 0.05 || weights[2] != 0 {
+		t.Log("Failure - Oja's rule did not find the principal component")
+		t.Log(weights)
+		t.Fail()
+		return
+	}
+	hebbian := NewMatrix(0.01, 2, []Layer {{Neurons: 1, Activation: Activations["identity"], Rules: [][]Rule {{Rules["hebbian"], Rules["hebbian"], {}}}}}, [][][]float64 {{{0.3, -0.1, 0}}})
+	hebbian.Adapt(regimen, 5)
+	if growth := hebbian.Weights[0][0]; math.Sqrt(growth[0] * growth[0] + growth[1] * growth[1]) < 10 {
+		t.Log("Failure - Plain Hebbian learning did not grow without bound")
+		t.Log(growth)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Oja's rule normalizes Hebbian growth onto the principal component")
+}
+
+func Test_Network_Plasticity_Matrix (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 2000
+	layers := []Layer {
+		{Neurons: 3, Activation: Activations["sigmoid"], Rules: [][]Rule {{Rules["hebbian"], {}, Rules["oja"]}, {Rules["bcm"], Rules["bcm"], Rules["bcm"]}, {{}, {}, {}}}},
+		{Neurons: 1, Activation: Activations["sigmoid"]},
+	}
+	weights := RandomWeights(2, layers, 13)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	var supervised [][][]float64
+
+	go func() {
+		var divergences []Divergence
+		for epoch := 0; epoch < 5; epoch++ {
+			divergences = append(divergences, CrossCheck(network, matrix, pruningRegimen)...)
+		}
+		supervised = copyWeights(matrix.Weights)
+		inputs := [][]float64 {{0, 1}, {1, 0}, {1, 1}}
+		for epoch := 0; epoch < 5; epoch++ {
+			var divergence Divergence
+			expected := matrix.AdaptBatch(inputs)
+			for s, output := range network.AdaptBatch(inputs) {
+				divergence.Output = math.Max(divergence.Output, math.Abs(output[0] - expected[s][0]))
+			}
+			for l, layer := range network.Weights() {
+				for j := range layer {
+					for i, weight := range layer[j] {
+						divergence.Weight = math.Max(divergence.Weight, math.Abs(weight - matrix.Weights[l][j][i]))
+					}
+				}
+			}
+			divergences = append(divergences, divergence)
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Plastic network timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Plastic network diverged from the matrix engine")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		for l := range supervised {
+			for j := range supervised[l] {
+				for i, weight := range supervised[l][j] {
+					if (layers[l].Rules != nil && layers[l].Rules[j][i].Delta != nil) == (matrix.Weights[l][j][i] == weight) {
+						t.Log("Failure - Adaptation moved the wrong synapses")
+						t.Log(l, j, i)
+						t.Fail()
+						return
+					}
+				}
+			}
+		}
+		var buffer bytes.Buffer
+		matrix.Save(&buffer)
+		model, err := LoadModel(&buffer)
+		if err != nil || model.Layers[0].Rules[1][2].Name != "bcm" || model.Layers[0].Rules[0][1].Delta != nil || model.Layers[1].Rules != nil {
+			t.Log("Failure - Learning rules lost in serialization")
+			t.Log(err)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Local learning rules match the matrix engine")
+		return
+	}
+}
@@ -0,0 +1,223 @@
+package ann
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+type Preprocessor interface {
+	Kind () string
+	Fit (regimen Regimen)
+	Transform (input []float64) []float64
+}
+
+var Preprocessors = map[string]func () Preprocessor {
+	"minmax": func () Preprocessor {return &MinMax {}},
+	"standardize": func () Preprocessor {return &Standardize {}},
+	"onehot": func () Preprocessor {return &OneHot {}},
+	"polynomial": func () Preprocessor {return &Polynomial {}},
+}
+
+type Pipeline []Preprocessor
+
+type MinMax struct {
+	Low float64
+	High float64
+	Min []float64
+	Max []float64
+}
+
+type Standardize struct {
+	Mean []float64
+	StdDev []float64
+}
+
+type OneHot struct {
+	Columns []int
+	Categories [][]float64
+}
+
+type Polynomial struct {
+	Degree int
+}
+
+func (pipeline Pipeline) Fit (regimen Regimen) {
+	for _, step := range pipeline {
+		step.Fit(regimen)
+		regimen = Pipeline {step}.Apply(regimen)
+	}
+}
+
+func (pipeline Pipeline) Transform (input []float64) []float64 {
+	for _, step := range pipeline {
+		input = step.Transform(input)
+	}
+	return input
+}
+
+func (pipeline Pipeline) Apply (regimen Regimen) Regimen {
+	applied := Regimen {TrainingSets: make([]TrainingSet, len(regimen.TrainingSets))}
+	for s, set := range regimen.TrainingSets {
+		applied.TrainingSets[s] = TrainingSet {Input: pipeline.Transform(set.Input), Expect: set.Expect}
+	}
+	return applied
+}
+
+func (pipeline Pipeline) MarshalJSON () ([]byte, error) {
+	type step struct {
+		Kind string
+		Step Preprocessor
+	}
+	steps := make([]step, len(pipeline))
+	for s, preprocessor := range pipeline {
+		steps[s] = step {Kind: preprocessor.Kind(), Step: preprocessor}
+	}
+	return json.Marshal(steps)
+}
+
+func (pipeline *Pipeline) UnmarshalJSON (data []byte) error {
+	var steps []struct {
+		Kind string
+		Step json.RawMessage
+	}
+	if err := json.Unmarshal(data, &steps); err != nil {return err}
+	*pipeline = nil
+	for _, step := range steps {
+		build, ok := Preprocessors[step.Kind]
+		if !ok {return fmt.Errorf("ann: unknown preprocessor %q", step.Kind)}
+		preprocessor := build()
+		if err := json.Unmarshal(step.Step, preprocessor); err != nil {return err}
+		*pipeline = append(*pipeline, preprocessor)
+	}
+	return nil
+}
+
+func (minmax *MinMax) Kind () string {return "minmax"}
+
+func (minmax *MinMax) Fit (regimen Regimen) {
+	if minmax.Low == minmax.High {minmax.Low, minmax.High = 0, 1}
+	minmax.Min, minmax.Max = nil, nil
+	for _, set := range regimen.TrainingSets {
+		if minmax.Min == nil {
+			minmax.Min = append([]float64 {}, set.Input...)
+			minmax.Max = append([]float64 {}, set.Input...)
+		}
+		for i, value := range set.Input {
+			minmax.Min[i] = math.Min(minmax.Min[i], value)
+			minmax.Max[i] = math.Max(minmax.Max[i], value)
+		}
+	}
+}
+
+func (minmax *MinMax) Transform (input []float64) []float64 {
+	output := make([]float64, len(input))
+	for i, value := range input {
+		span := minmax.Max[i] - minmax.Min[i]
+		if span == 0 {
+			output[i] = minmax.Low
+			continue
+		}
+		output[i] = minmax.Low + (value - minmax.Min[i]) / span * (minmax.High - minmax.Low)
+	}
+	return output
+}
+
+func (standardize *Standardize) Kind () string {return "standardize"}
+
+func (standardize *Standardize) Fit (regimen Regimen) {
+	count := float64(len(regimen.TrainingSets))
+	standardize.Mean, standardize.StdDev = nil, nil
+	for _, set := range regimen.TrainingSets {
+		if standardize.Mean == nil {
+			standardize.Mean = make([]float64, len(set.Input))
+			standardize.StdDev = make([]float64, len(set.Input))
+		}
+		for i, value := range set.Input {
+			standardize.Mean[i] = standardize.Mean[i] + value / count
+		}
+	}
+	for _, set := range regimen.TrainingSets {
+		for i, value := range set.Input {
+			deviation := value - standardize.Mean[i]
+			standardize.StdDev[i] = standardize.StdDev[i] + deviation * deviation / count
+		}
+	}
+	for i := range standardize.StdDev {
+		standardize.StdDev[i] = math.Sqrt(standardize.StdDev[i])
+	}
+}
+
+func (standardize *Standardize) Transform (input []float64) []float64 {
+	output := make([]float64, len(input))
+	for i, value := range input {
+		output[i] = value - standardize.Mean[i]
+		if standardize.StdDev[i] != 0 {output[i] = output[i] / standardize.StdDev[i]}
+	}
+	return output
+}
+
+func (onehot *OneHot) Kind () string {return "onehot"}
+
+func (onehot *OneHot) Fit (regimen Regimen) {
+	onehot.Categories = make([][]float64, len(onehot.Columns))
+	for c, column := range onehot.Columns {
+		seen := map[float64]bool {}
+		for _, set := range regimen.TrainingSets {
+			if value := set.Input[column]; !seen[value] {
+				seen[value] = true
+				onehot.Categories[c] = append(onehot.Categories[c], value)
+			}
+		}
+	}
+}
+
+func (onehot *OneHot) Transform (input []float64) []float64 {
+	var output []float64
+	encoded := map[int]int {}
+	for c, column := range onehot.Columns {
+		encoded[column] = c
+	}
+	for i, value := range input {
+		c, ok := encoded[i]
+		if !ok {
+			output = append(output, value)
+			continue
+		}
+		for _, category := range onehot.Categories[c] {
+			if value == category {
+				output = append(output, 1)
+			} else {
+				output = append(output, 0)
+			}
+		}
+	}
+	return output
+}
+
+func (polynomial *Polynomial) Kind () string {return "polynomial"}
+
+func (polynomial *Polynomial) Fit (regimen Regimen) {}
+
+func (polynomial *Polynomial) Transform (input []float64) []float64 {
+	output := append([]float64 {}, input...)
+	terms := make([][]int, len(input))
+	for i := range input {
+		terms[i] = []int {i}
+	}
+	for degree := 2; degree <= polynomial.Degree; degree++ {
+		var next [][]int
+		for _, term := range terms {
+			for i := term[len(term) - 1]; i < len(input); i++ {
+				product := 1.0
+				for _, factor := range term {
+					product = product * input[factor]
+				}
+				output = append(output, product * input[i])
+				next = append(next, append(append([]int {}, term...), i))
+			}
+		}
+		terms = next
+	}
+	return output
+}
@@ -0,0 +1,126 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+var rawRegimen = Regimen {
+	TrainingSets: []TrainingSet {
+		{Input: []float64 {1246, 2, 0}, Expect: []float64 {1}},
+		{Input: []float64 {246, 1, 1}, Expect: []float64 {0}},
+		{Input: []float64 {746, 2, 2}, Expect: []float64 {1}},
+	},
+}
+
+func Test_MinMax_Transform_Range (t *testing.T) {
+	minmax := &MinMax {}
+	minmax.Fit(rawRegimen)
+	result := minmax.Transform([]float64 {1246, 1.5, 1})
+	if result[0] != 1 || result[1] != 0.5 || result[2] != 0.5 {
+		t.Log("Failure - MinMax scaling inaccurate")
+		t.Log(result)
+		t.Fail()
+		return
+	}
+	t.Log("Success - MinMax scaling is accurate")
+}
+
+func Test_Standardize_Transform_Moments (t *testing.T) {
+	standardize := &Standardize {}
+	standardize.Fit(rawRegimen)
+	var mean, variance float64
+	for _, set := range rawRegimen.TrainingSets {
+		mean = mean + standardize.Transform(set.Input)[0] / 3
+	}
+	for _, set := range rawRegimen.TrainingSets {
+		variance = variance + math.Pow(standardize.Transform(set.Input)[0] - mean, 2) / 3
+	}
+	if math.Abs(mean) > 1e-9 || math.Abs(variance - 1) > 1e-9 {
+		t.Log("Failure - Standardize moments inaccurate")
+		t.Log(mean, variance)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Standardize moments are accurate")
+}
+
+func Test_OneHot_Transform_Encoding (t *testing.T) {
+	onehot := &OneHot {Columns: []int {2}}
+	onehot.Fit(rawRegimen)
+	result := onehot.Transform([]float64 {5, 6, 1})
+	expect := []float64 {5, 6, 0, 1, 0}
+	if len(result) != len(expect) {
+		t.Log("Failure - OneHot encoding has wrong width")
+		t.Fail()
+		return
+	}
+	for i := range expect {
+		if result[i] != expect[i] {
+			t.Log("Failure - OneHot encoding inaccurate")
+			t.Log(result)
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - OneHot encoding is accurate")
+}
+
+func Test_Polynomial_Transform_Terms (t *testing.T) {
+	polynomial := &Polynomial {Degree: 2}
+	result := polynomial.Transform([]float64 {2, 3})
+	expect := []float64 {2, 3, 4, 6, 9}
+	for i := range expect {
+		if len(result) != len(expect) || result[i] != expect[i] {
+			t.Log("Failure - Polynomial terms inaccurate")
+			t.Log(result)
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Polynomial terms are accurate")
+}
+
+func Test_Pipeline_Save_Load (t *testing.T) {
+	resultchan := make(chan struct{})
+	var timeout time.Duration = 100
+	pipeline := Pipeline {&OneHot {Columns: []int {2}}, &MinMax {}}
+	pipeline.Fit(rawRegimen)
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	network, _ := NewNetwork(0.1, 5, layers, RandomWeights(5, layers, 3))
+	network.Pipeline = pipeline
+
+	go func() {
+		var buffer bytes.Buffer
+		if err := network.Save(&buffer); err != nil {
+			t.Log("Failure - Network save returned an error")
+			t.Log(err)
+			return
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Log("Failure - Network load returned an error")
+			t.Log(err)
+			return
+		}
+		for _, set := range rawRegimen.TrainingSets {
+			if network.Predict(set.Input)[0] != loaded.Predict(set.Input)[0] {
+				t.Log("Failure - Loaded network predicts differently")
+				return
+			}
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network save and load timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Network save and load preserve predictions")
+		return
+	}
+}
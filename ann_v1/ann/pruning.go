@@ -0,0 +1,142 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const devpruning bool = false
+
+type Pruning struct {
+	Method string
+	Threshold float64
+	Epochs int
+	Batch int
+}
+
+type Compression struct {
+	Before int
+	After int
+	Ratio float64
+	Loss float64
+	PrunedLoss float64
+	TunedLoss float64
+}
+
+type tunable interface {
+	Predictor
+	TrainBatches (regimen Regimen, epochs int, size int)
+}
+
+func Parameters (layers []Layer, weights [][][]float64) int {
+	count := 0
+	for l := range weights {
+		for j := range weights[l] {
+			for i := range weights[l][j] {
+				if l < len(layers) && layers[l].Mask != nil && !layers[l].Mask[j][i] {continue}
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (pruning Pruning) saliency (matrix *Matrix, regimen Regimen) ([][][]float64, error) {
+	saliency := zeroWeights(matrix.Weights)
+	switch pruning.Method {
+	case "magnitude":
+		for l := range saliency {
+			for j := range saliency[l] {
+				for i := range saliency[l][j] {
+					saliency[l][j][i] = math.Abs(matrix.Weights[l][j][i])
+				}
+			}
+		}
+	case "gradient":
+		probe := matrix.Model().Matrix()
+		probe.LearnRate = 1
+		probe.LearnBatch(regimen.TrainingSets)
+		for l := range saliency {
+			for j := range saliency[l] {
+				for i, weight := range matrix.Weights[l][j] {
+					saliency[l][j][i] = math.Abs(weight * (probe.Weights[l][j][i] - weight))
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("ann: unknown pruning method %q", pruning.Method)
+	}
+	return saliency, nil
+}
+
+func (pruning Pruning) compress (matrix *Matrix, regimen Regimen) ([]Layer, [][][]float64, Compression, error) {
+	compression := Compression {Before: Parameters(matrix.Layers, matrix.Weights), Loss: Evaluate(matrix, regimen).Loss}
+	saliency, err := pruning.saliency(matrix, regimen)
+	if err != nil {return nil, nil, compression, err}
+	layers, weights := append([]Layer {}, matrix.Layers...), copyWeights(matrix.Weights)
+	for l := range layers {
+		if rewirable(layers, l) != nil {continue}
+		fanin := matrix.Inputs
+		if l > 0 {fanin = layers[l - 1].Neurons}
+		mask := copyMask(layers[l].Mask)
+		for j := range weights[l] {
+			for i := range weights[l][j] {
+				if i == fanin || saliency[l][j][i] >= pruning.Threshold {continue}
+				if mask == nil {mask = fullMask(weights[l])}
+				mask[j][i], weights[l][j][i] = false, 0
+			}
+		}
+		layers[l].Mask = mask
+	}
+	compression.After = Parameters(layers, weights)
+	if compression.After > 0 {compression.Ratio = float64(compression.Before) / float64(compression.After)}
+	if devpruning {fmt.Printf("\n%v: Pruned %d of %d parameters...\n", time.Now(), compression.Before - compression.After, compression.Before)}
+	return layers, weights, compression, nil
+}
+
+func (pruning Pruning) tune (engine tunable, regimen Regimen, compression Compression) Compression {
+	compression.PrunedLoss = Evaluate(engine, regimen).Loss
+	compression.TunedLoss = compression.PrunedLoss
+	if pruning.Epochs <= 0 || len(regimen.TrainingSets) == 0 {return compression}
+	batch := pruning.Batch
+	if batch <= 0 {batch = 1}
+	engine.TrainBatches(regimen, pruning.Epochs, batch)
+	compression.TunedLoss = Evaluate(engine, regimen).Loss
+	return compression
+}
+
+func (network *Network) Prune (pruning Pruning, regimen Regimen) (Compression, error) {
+	network.release()
+	layers, _, compression, err := pruning.compress(network.Model().Matrix(), regimen)
+	if err != nil {return compression, err}
+	for l := range layers {
+		if layers[l].Mask != nil && network.splices[l] == nil {return compression, fmt.Errorf("ann: layer %d has no synapses to prune", l)}
+	}
+	for l := range layers {
+		if layers[l].Mask == nil {continue}
+		upstream := network.upstream(l)
+		touched := map[*junction]bool {}
+		for j := range layers[l].Mask {
+			for i, present := range layers[l].Mask[j] {
+				if present || network.splices[l][j][i] == nil {continue}
+				network.disconnect(l, j, i)
+				touched[upstream[i]], touched[network.nodes[l + 1][j]] = true, true
+			}
+		}
+		var junctions []*junction
+		for junction := range touched {
+			junctions = append(junctions, junction)
+		}
+		network.settle(layers, l, junctions)
+	}
+	network.Layers = layers
+	return pruning.tune(network, regimen, compression), nil
+}
+
+func (matrix *Matrix) Prune (pruning Pruning, regimen Regimen) (Compression, error) {
+	layers, weights, compression, err := pruning.compress(matrix, regimen)
+	if err != nil {return compression, err}
+	matrix.Layers, matrix.Weights = layers, weights
+	return pruning.tune(matrix, regimen, compression), nil
+}
@@ -0,0 +1,98 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var pruningRegimen = Regimen {TrainingSets: []TrainingSet {
+	{Input: []float64 {0, 1}, Expect: []float64 {1}},
+	{Input: []float64 {1, 0}, Expect: []float64 {1}},
+	{Input: []float64 {1, 1}, Expect: []float64 {0}},
+	{Input: []float64 {0, 0}, Expect: []float64 {0}},
+}}
+
+func Test_Matrix_Pruning_Magnitude (t *testing.T) {
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := [][][]float64 {{{0.05, 0.8, 0.01}, {-0.02, -0.9, 0.7}}, {{0.6, -0.04, 0.3}}}
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	compression, err := matrix.Prune(Pruning {Method: "magnitude", Threshold: 0.1}, pruningRegimen)
+	if err != nil || compression.Before != 9 || compression.After != 6 || compression.Ratio != 1.5 {
+		t.Log("Failure - Magnitude pruning reported the wrong compression")
+		t.Log(err, compression)
+		t.Fail()
+		return
+	}
+	mask := matrix.Layers[0].Mask
+	if mask[0][0] || mask[1][0] || !mask[0][2] || !mask[1][1] || matrix.Layers[1].Mask[0][1] || matrix.Weights[0][0][0] != 0 || weights[0][0][0] != 0.05 {
+		t.Log("Failure - Magnitude pruning removed the wrong synapses")
+		t.Log(matrix.Layers[0].Mask, matrix.Layers[1].Mask, matrix.Weights)
+		t.Fail()
+		return
+	}
+	if _, err := matrix.Prune(Pruning {Method: "random"}, pruningRegimen); err == nil {
+		t.Log("Failure - Pruning accepted an unknown method")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Magnitude pruning removes small weights and keeps biases")
+}
+
+func Test_Network_Pruning_Matrix (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 2000
+	layers := []Layer {{Neurons: 4, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 37)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	pruning := Pruning {Method: "gradient", Threshold: 0.01, Epochs: 20, Batch: 2}
+	var compressions [2]Compression
+	bias := network.probes[1][0][4]
+
+	go func() {
+		var divergences []Divergence
+		compressions[0], _ = network.Prune(pruning, pruningRegimen)
+		compressions[1], _ = matrix.Prune(pruning, pruningRegimen)
+		divergences = append(divergences, CrossCheck(network, matrix, pruningRegimen)...)
+		if network.probes[1][0][4] != bias {divergences = append(divergences, Divergence {Weight: math.Inf(1)})}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Pruned network timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Pruned network diverged from the matrix engine")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		if compressions[0].After >= compressions[0].Before || compressions[0].After != compressions[1].After || math.Abs(compressions[0].TunedLoss - compressions[1].TunedLoss) > 1e-9 || compressions[0].TunedLoss >= compressions[0].PrunedLoss {
+			t.Log("Failure - Gradient pruning compressions disagree or fine-tuning did not help")
+			t.Log(compressions)
+			t.Fail()
+			return
+		}
+		for l, layer := range matrix.Layers {
+			for j := range layer.Mask {
+				for i, present := range layer.Mask[j] {
+					if !present && matrix.Weights[l][j][i] != 0 {
+						t.Log("Failure - Fine-tuning revived a pruned synapse")
+						t.Log(l, j, i)
+						t.Fail()
+						return
+					}
+				}
+			}
+		}
+		t.Log("Success - Gradient pruning and fine-tuning match the matrix engine")
+		return
+	}
+}
@@ -0,0 +1,274 @@
+package ann
+
+import (
+	"fmt"
+	"time"
+)
+
+const devrecurrent bool = false
+
+type Tick struct {
+	Sample int
+	Step int
+	Width int
+	Infer bool
+	Start bool
+	Reset bool
+	Backward bool
+}
+
+func Delay (source int, clock chan Tick, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	var state Signal
+	var pending bool
+	start, outstanding := 0, 0
+	relay := func (margin Signal) bool {
+		outstanding--
+		if margin.Step == start {return true}
+		margin.Step, margin.Source = margin.Step - 1, source
+		return PushSignalOrCancel(margin, peripherals.Downfeed, cancelchan)
+	}
+	for {
+		select {
+		case tick := <- clock:
+			if tick.Backward {
+				outstanding = tick.Step - start + 1
+				if !PushSignalOrCancel(Signal {Sample: tick.Sample, Step: tick.Step, Source: source, Infer: tick.Infer, Values: make([]float64, tick.Width)}, peripherals.Downfeed, cancelchan) {return}
+				continue
+			}
+			for outstanding > 0 {
+				ok, margin := PullSignalOrCancel(peripherals.Upfeed, cancelchan)
+				if !ok || !relay(margin) {return}
+			}
+			if pending {
+				ok, input := PullSignalOrCancel(peripherals.Input, cancelchan)
+				if !ok {return}
+				state = input
+			}
+			if tick.Reset {state = Signal {}}
+			if tick.Start {start = tick.Step}
+			output := Signal {Sample: tick.Sample, Step: tick.Step, Infer: tick.Infer, Values: make([]float64, tick.Width)}
+			copy(output.Values, state.Values)
+			pending = true
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if devrecurrent {fmt.Printf("\n%v: Delay replayed step %d %v...\n", time.Now(), output.Step, output.Values)}
+		case input := <- peripherals.Input:
+			state, pending = input, false
+		case margin := <- peripherals.Upfeed:
+			if !relay(margin) {return}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func (network *Network) echo (delays []*junction, k int, neuron *junction) {
+	if delays == nil {return}
+	clock := make(chan Tick)
+	state := make(chan Signal)
+	delay := delays[k]
+	network.clocks, delay.clocks = append(network.clocks, clock), append(delay.clocks, clock)
+	go Delay (len(neuron.lanes), clock, SignalPeripherals {Input: state, Output: delay.node.Input, Upfeed: delay.node.Downfeed, Downfeed: neuron.node.Upfeed}, delay.cancelchan)
+	neuron.lanes = append(neuron.lanes, state)
+}
+
+func window (steps int, size int) int {
+	if size <= 0 || size > steps {return steps}
+	return size
+}
+
+type Sequence []TrainingSet
+
+type SequenceRegimen struct {
+	Sequences []Sequence
+}
+
+func longest (sequences []Sequence) int {
+	steps := 0
+	for _, sequence := range sequences {
+		if len(sequence) > steps {steps = len(sequence)}
+	}
+	return steps
+}
+
+func (sequence Sequence) errormargins (t int, output []float64) []float64 {
+	errormargins := make([]float64, len(output))
+	if t >= len(sequence) || len(sequence[t].Expect) == 0 {return errormargins}
+	for j := range errormargins {
+		errormargins[j] = sequence[t].Expect[j] - output[j]
+	}
+	return errormargins
+}
+
+func (sequence Sequence) Inputs () [][]float64 {
+	inputs := make([][]float64, len(sequence))
+	for t, set := range sequence {
+		inputs[t] = set.Input
+	}
+	return inputs
+}
+
+func SequenceError (sequence Sequence, outputs [][]float64) float64 {
+	var sum float64
+	for t, set := range sequence {
+		if len(set.Expect) > 0 {sum = sum + SquaredError(set.Expect, outputs[t])}
+	}
+	return sum
+}
+
+func (network *Network) LearnSequences (sequences []Sequence, size int) [][][]float64 {
+	if len(sequences) == 0 {return nil}
+	steps := longest(sequences)
+	size = window(steps, size)
+	outputs := make([][][]float64, len(sequences))
+	network.release()
+	for start := 0; start < steps; start = start + size {
+		end := start + size
+		if end > steps {end = steps}
+		network.sample++
+		for t := start; t < end; t++ {
+			inputs := make([][]float64, len(sequences))
+			for s, sequence := range sequences {
+				inputs[s] = make([]float64, network.Inputs)
+				if t < len(sequence) {inputs[s] = network.Pipeline.Transform(sequence[t].Input)}
+			}
+			network.feed(inputs, Tick {Sample: network.sample, Step: t, Start: t == start, Reset: t == 0})
+			_, rows := network.collect()
+			for s, row := range rows {
+				outputs[s] = append(outputs[s], row)
+			}
+		}
+		for t := end - 1; t >= start; t-- {
+			errormargins := make([][]float64, len(sequences))
+			for s, sequence := range sequences {
+				errormargins[s] = sequence.errormargins(t, outputs[s][t])
+			}
+			network.backward(errormargins, t, t == end - 1, false)
+		}
+	}
+	for s, sequence := range sequences {
+		outputs[s] = outputs[s][:len(sequence)]
+	}
+	return outputs
+}
+
+func (network *Network) LearnSequence (sequence Sequence, size int) [][]float64 {
+	return network.LearnSequences([]Sequence {sequence}, size)[0]
+}
+
+func (network *Network) PredictSequence (inputs [][]float64) [][]float64 {
+	outputs := make([][]float64, len(inputs))
+	for t, input := range inputs {
+		network.feed([][]float64 {network.Pipeline.Transform(input)}, Tick {Step: t, Infer: true, Start: t == 0, Reset: t == 0})
+		_, rows := network.collect()
+		outputs[t] = rows[0]
+	}
+	return outputs
+}
+
+func (network *Network) TrainSequences (regimen SequenceRegimen, epochs int, size int) {
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, sequence := range regimen.Sequences {
+			network.LearnSequence(sequence, size)
+		}
+	}
+}
+
+func (network *Network) SequenceLoss (regimen SequenceRegimen) float64 {
+	var loss float64
+	for _, sequence := range regimen.Sequences {
+		loss = loss + SequenceError(sequence, network.PredictSequence(sequence.Inputs()))
+	}
+	return loss / float64(len(regimen.Sequences))
+}
+
+func (matrix *Matrix) layerOutputs (signals [][]float64) [][]float64 {
+	outputs := make([][]float64, len(matrix.Layers))
+	for l, layer := range matrix.Layers {
+		outputs[l] = append([]float64 {}, signals[l + 1][:layer.Neurons]...)
+	}
+	return outputs
+}
+
+func (matrix *Matrix) LearnSequences (sequences []Sequence, size int) [][][]float64 {
+	if len(sequences) == 0 {return nil}
+	steps := longest(sequences)
+	size = window(steps, size)
+	outputs := make([][][]float64, len(sequences))
+	previous := make([][][]float64, len(sequences))
+	states := make([][][]float64, len(sequences))
+	for start := 0; start < steps; start = start + size {
+		end := start + size
+		if end > steps {end = steps}
+		adjustments := matrix.adjustments()
+		active := make([][]int, end - start)
+		signals := make([][][][]float64, end - start)
+		excitements := make([][][][]float64, end - start)
+		carried := make([][][][]float64, end - start)
+		for t := start; t < end; t++ {
+			var inputs [][]float64
+			var contexts [][][]float64
+			for s, sequence := range sequences {
+				if t >= len(sequence) {continue}
+				active[t - start] = append(active[t - start], s)
+				inputs = append(inputs, matrix.Pipeline.Transform(sequence[t].Input))
+				contexts = append(contexts, previous[s])
+				carried[t - start] = append(carried[t - start], states[s])
+			}
+			var next [][][]float64
+			signals[t - start], excitements[t - start], next = matrix.steps(inputs, contexts, carried[t - start], false)
+			for k, s := range active[t - start] {
+				states[s] = next[k]
+				previous[s] = matrix.layerOutputs(signals[t - start][k])
+				outputs[s] = append(outputs[s], previous[s][len(matrix.Layers) - 1])
+			}
+		}
+		carry := make([][][]float64, len(sequences))
+		cellcarry := make([][][]float64, len(sequences))
+		for t := end - 1; t >= start; t-- {
+			errormargins := make([][]float64, len(active[t - start]))
+			carries := make([][][]float64, len(active[t - start]))
+			cellcarries := make([][][]float64, len(active[t - start]))
+			for k, s := range active[t - start] {
+				errormargins[k] = sequences[s].errormargins(t, outputs[s][t])
+				carries[k], cellcarries[k] = carry[s], cellcarry[s]
+			}
+			_, recurrent, cellmargins := matrix.backsteps(signals[t - start], excitements[t - start], carried[t - start], errormargins, carries, cellcarries, adjustments)
+			for k, s := range active[t - start] {
+				carry[s], cellcarry[s] = recurrent[k], cellmargins[k]
+			}
+		}
+		matrix.Adjust(adjustments)
+	}
+	return outputs
+}
+
+func (matrix *Matrix) LearnSequence (sequence Sequence, size int) [][]float64 {
+	return matrix.LearnSequences([]Sequence {sequence}, size)[0]
+}
+
+func (matrix *Matrix) PredictSequence (inputs [][]float64) [][]float64 {
+	outputs := make([][]float64, len(inputs))
+	var previous, states [][]float64
+	for t, input := range inputs {
+		signals, _, next := matrix.steps([][]float64 {matrix.Pipeline.Transform(input)}, [][][]float64 {previous}, [][][]float64 {states}, true)
+		previous, states = matrix.layerOutputs(signals[0]), next[0]
+		outputs[t] = previous[len(matrix.Layers) - 1]
+	}
+	return outputs
+}
+
+func (matrix *Matrix) TrainSequences (regimen SequenceRegimen, epochs int, size int) {
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, sequence := range regimen.Sequences {
+			matrix.LearnSequence(sequence, size)
+		}
+	}
+}
+
+func (matrix *Matrix) SequenceLoss (regimen SequenceRegimen) float64 {
+	var loss float64
+	for _, sequence := range regimen.Sequences {
+		loss = loss + SequenceError(sequence, matrix.PredictSequence(sequence.Inputs()))
+	}
+	return loss / float64(len(regimen.Sequences))
+}
@@ -0,0 +1,120 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func bits (steps int, seed int) Sequence {
+	sequence := make(Sequence, steps)
+	previous := 0.0
+	for t := range sequence {
+		current := float64((seed >> uint(t)) & 1)
+		sequence[t] = TrainingSet {Input: []float64 {current}, Expect: []float64 {previous, math.Abs(current - previous)}}
+		previous = current
+	}
+	return sequence
+}
+
+func Test_Matrix_Sequence_Gradients (t *testing.T) {
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {0, 1}}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(1, layers, 37)
+	sequence := bits(6, 45)
+	inputs := make([][]float64, len(sequence))
+	for s, set := range sequence {
+		inputs[s] = set.Input
+	}
+	matrix := NewMatrix(0.1, 1, layers, weights)
+	matrix.LearnSequence(sequence, 0)
+	loss := func (weights [][][]float64) float64 {
+		var sum float64
+		for s, output := range NewMatrix(0.1, 1, layers, weights).PredictSequence(inputs) {
+			sum = sum + SquaredError(sequence[s].Expect, output)
+		}
+		return sum
+	}
+	for l := range weights {
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				shifted := copyWeights(weights)
+				shifted[l][j][i] = weight + 1e-6
+				upper := loss(shifted)
+				shifted[l][j][i] = weight - 1e-6
+				lower := loss(shifted)
+				numeric := (upper - lower) / 2e-6
+				analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+				if math.Abs(numeric - analytic) > 1e-6 {
+					t.Log("Failure - Backpropagation through time disagrees with finite differences")
+					t.Log(l, j, i, analytic, numeric)
+					t.Fail()
+					return
+				}
+			}
+		}
+	}
+	t.Log("Success - Backpropagation through time agrees with finite differences")
+}
+
+func Test_Network_Sequence_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 1000
+	topologies := [][]Layer {
+		{{Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {0}}, {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{{Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {1}}, {Neurons: 2, Activation: Activations["sigmoid"]}},
+		{{Neurons: 2, Activation: Activations["sigmoid"], Recurrent: []int {0, 2}}, {Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {0}}, {Neurons: 2, Activation: Activations["sigmoid"]}},
+	}
+
+	go func() {
+		var divergence float64
+		for n, layers := range topologies {
+			weights := RandomWeights(1, layers, int64(n))
+			network, _ := NewNetwork(0.3, 1, layers, weights)
+			matrix := NewMatrix(0.3, 1, layers, weights)
+			sequences := []Sequence {bits(7, 83), bits(7, 54)}
+			for epoch := 0; epoch < 5; epoch++ {
+				expected := matrix.LearnSequences(sequences, 3)
+				for s, outputs := range network.LearnSequences(sequences, 3) {
+					for step := range outputs {
+						for j := range outputs[step] {
+							divergence = math.Max(divergence, math.Abs(outputs[step][j] - expected[s][step][j]))
+						}
+					}
+				}
+			}
+			after := network.Weights()
+			for l := range after {
+				for j := range after[l] {
+					for i := range after[l][j] {
+						divergence = math.Max(divergence, math.Abs(after[l][j][i] - matrix.Weights[l][j][i]))
+					}
+				}
+			}
+			inputs := [][]float64 {{1}, {0}, {0}, {1}}
+			expected := matrix.PredictSequence(inputs)
+			for step, output := range network.PredictSequence(inputs) {
+				for j := range output {
+					divergence = math.Max(divergence, math.Abs(output[j] - expected[step][j]))
+				}
+			}
+			network.Close()
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Recurrent network sequence learning timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Recurrent network diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Recurrent network matches the matrix engine")
+		return
+	}
+}
@@ -0,0 +1,160 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type Fold struct {
+	Train Regimen
+	Test Regimen
+}
+
+type CrossValidation struct {
+	Folds []map[string]float64
+	Mean map[string]float64
+	StdDev map[string]float64
+}
+
+func (set TrainingSet) Label () int {
+	if len(set.Expect) == 1 {
+		return int(math.Round(set.Expect[0]))
+	}
+	label := 0
+	for j := range set.Expect {
+		if set.Expect[j] > set.Expect[label] {label = j}
+	}
+	return label
+}
+
+func (regimen Regimen) Shuffle (seed int64) Regimen {
+	sets := append([]TrainingSet {}, regimen.TrainingSets...)
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(sets), func (i, j int) {sets[i], sets[j] = sets[j], sets[i]})
+	return Regimen {TrainingSets: sets}
+}
+
+func (regimen Regimen) Strata () [][]TrainingSet {
+	var labels []int
+	strata := map[int][]TrainingSet {}
+	for _, set := range regimen.TrainingSets {
+		label := set.Label()
+		if _, ok := strata[label]; !ok {labels = append(labels, label)}
+		strata[label] = append(strata[label], set)
+	}
+	sort.Ints(labels)
+	groups := make([][]TrainingSet, len(labels))
+	for g, label := range labels {
+		groups[g] = strata[label]
+	}
+	return groups
+}
+
+func apportion (total int, fractions []float64) []int {
+	var sum float64
+	for _, fraction := range fractions {
+		sum = sum + fraction
+	}
+	counts := make([]int, len(fractions))
+	var cumulative float64
+	assigned := 0
+	for f, fraction := range fractions {
+		cumulative = cumulative + fraction
+		counts[f] = int(math.Round(cumulative / sum * float64(total))) - assigned
+		assigned = assigned + counts[f]
+	}
+	return counts
+}
+
+func (regimen Regimen) Partition (seed int64, fractions ...float64) []Regimen {
+	sets := regimen.Shuffle(seed).TrainingSets
+	parts := make([]Regimen, len(fractions))
+	start := 0
+	for p, count := range apportion(len(sets), fractions) {
+		parts[p].TrainingSets = sets[start:start + count:start + count]
+		start = start + count
+	}
+	return parts
+}
+
+func (regimen Regimen) StratifiedPartition (seed int64, fractions ...float64) []Regimen {
+	parts := make([]Regimen, len(fractions))
+	for g, group := range regimen.Strata() {
+		for p, part := range (Regimen {TrainingSets: group}).Partition(seed + int64(g), fractions...) {
+			parts[p].TrainingSets = append(parts[p].TrainingSets, part.TrainingSets...)
+		}
+	}
+	for p := range parts {
+		parts[p] = parts[p].Shuffle(seed)
+	}
+	return parts
+}
+
+func assemble (parts []Regimen) []Fold {
+	folds := make([]Fold, len(parts))
+	for k := range parts {
+		folds[k].Test = parts[k]
+		for p := range parts {
+			if p != k {folds[k].Train.TrainingSets = append(folds[k].Train.TrainingSets, parts[p].TrainingSets...)}
+		}
+	}
+	return folds
+}
+
+func evenly (k int) []float64 {
+	fractions := make([]float64, k)
+	for f := range fractions {
+		fractions[f] = 1
+	}
+	return fractions
+}
+
+func (regimen Regimen) foldable (k int) error {
+	if k < 2 || k > len(regimen.TrainingSets) {
+		return fmt.Errorf("ann: cannot split %d training sets into %d folds", len(regimen.TrainingSets), k)
+	}
+	return nil
+}
+
+func (regimen Regimen) Folds (k int, seed int64) ([]Fold, error) {
+	if err := regimen.foldable(k); err != nil {return nil, err}
+	return assemble(regimen.Partition(seed, evenly(k)...)), nil
+}
+
+func (regimen Regimen) StratifiedFolds (k int, seed int64) ([]Fold, error) {
+	if err := regimen.foldable(k); err != nil {return nil, err}
+	return assemble(regimen.StratifiedPartition(seed, evenly(k)...)), nil
+}
+
+func CrossValidate (folds []Fold, build func () *Network, epochs int) CrossValidation {
+	validation := CrossValidation {Mean: map[string]float64 {}, StdDev: map[string]float64 {}}
+	for _, fold := range folds {
+		if len(fold.Test.TrainingSets) == 0 {continue}
+		network := build()
+		network.Train(fold.Train, epochs)
+		validation.Folds = append(validation.Folds, Evaluate(network, fold.Test).Metrics())
+		network.Close()
+	}
+	counts := map[string]float64 {}
+	for _, metrics := range validation.Folds {
+		for metric, value := range metrics {
+			counts[metric]++
+			validation.Mean[metric] = validation.Mean[metric] + value
+		}
+	}
+	for metric, count := range counts {
+		validation.Mean[metric] = validation.Mean[metric] / count
+	}
+	for _, metrics := range validation.Folds {
+		for metric, value := range metrics {
+			deviation := value - validation.Mean[metric]
+			validation.StdDev[metric] = validation.StdDev[metric] + deviation * deviation / counts[metric]
+		}
+	}
+	for metric, variance := range validation.StdDev {
+		validation.StdDev[metric] = math.Sqrt(variance)
+	}
+	return validation
+}
@@ -0,0 +1,214 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func labelled (count int, labels int) Regimen {
+	var regimen Regimen
+	for s := 0; s < count; s++ {
+		regimen.TrainingSets = append(regimen.TrainingSets, TrainingSet {Input: []float64 {float64(s)}, Expect: []float64 {float64(s % labels)}})
+	}
+	return regimen
+}
+
+func Test_Regimen_Shuffle_Deterministic (t *testing.T) {
+	regimen := labelled(20, 2)
+	first := regimen.Shuffle(7); second := regimen.Shuffle(7)
+	for s := range first.TrainingSets {
+		if first.TrainingSets[s].Input[0] != second.TrainingSets[s].Input[0] {
+			t.Log("Failure - Regimen shuffle is not deterministic")
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Regimen shuffle is deterministic")
+}
+
+func Test_Regimen_Partition_Sizes (t *testing.T) {
+	parts := labelled(10, 2).Partition(1, 0.6, 0.2, 0.2)
+	if len(parts[0].TrainingSets) != 6 || len(parts[1].TrainingSets) != 2 || len(parts[2].TrainingSets) != 2 {
+		t.Log("Failure - Regimen partition sizes inaccurate")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Regimen partition sizes are accurate")
+}
+
+func Test_Regimen_Partition_Disjoint (t *testing.T) {
+	seen := map[float64]bool {}
+	for _, part := range labelled(25, 3).Partition(3, 0.5, 0.3, 0.2) {
+		for _, set := range part.TrainingSets {
+			if seen[set.Input[0]] {
+				t.Log("Failure - Regimen partition repeats a training set")
+				t.Fail()
+				return
+			}
+			seen[set.Input[0]] = true
+		}
+	}
+	if len(seen) != 25 {
+		t.Log("Failure - Regimen partition lost training sets")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Regimen partition is disjoint and complete")
+}
+
+func Test_Regimen_StratifiedPartition_Balance (t *testing.T) {
+	parts := labelled(40, 4).StratifiedPartition(5, 0.5, 0.5)
+	for _, part := range parts {
+		counts := map[int]int {}
+		for _, set := range part.TrainingSets {
+			counts[set.Label()]++
+		}
+		for label := 0; label < 4; label++ {
+			if counts[label] != 5 {
+				t.Log("Failure - Regimen stratified partition is unbalanced")
+				t.Log(counts)
+				t.Fail()
+				return
+			}
+		}
+	}
+	t.Log("Success - Regimen stratified partition is balanced")
+}
+
+func Test_Regimen_Folds_Coverage (t *testing.T) {
+	folds, err := labelled(12, 2).Folds(4, 9)
+	if err != nil {
+		t.Log("Failure - Regimen folds rejected a valid fold count")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	tested := 0
+	for _, fold := range folds {
+		if len(fold.Train.TrainingSets) + len(fold.Test.TrainingSets) != 12 {
+			t.Log("Failure - Regimen fold does not cover the regimen")
+			t.Fail()
+			return
+		}
+		tested = tested + len(fold.Test.TrainingSets)
+	}
+	if tested != 12 {
+		t.Log("Failure - Regimen folds do not test every training set once")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Regimen folds cover the regimen")
+}
+
+func Test_Regimen_Folds_Count (t *testing.T) {
+	regimen := labelled(3, 2)
+	for _, k := range []int {0, 1, 4} {
+		if _, err := regimen.Folds(k, 1); err == nil {
+			t.Log("Failure - Regimen folds accepted an invalid fold count")
+			t.Log(k)
+			t.Fail()
+			return
+		}
+		if _, err := regimen.StratifiedFolds(k, 1); err == nil {
+			t.Log("Failure - Regimen stratified folds accepted an invalid fold count")
+			t.Log(k)
+			t.Fail()
+			return
+		}
+	}
+	folds, _ := labelled(2, 2).StratifiedFolds(2, 1)
+	layers := []Layer {{Neurons: 1, Activation: Activation {Function: Sigmoid, Derivative: SigmoidDerivative}}}
+	build := func () *Network {network, _ := NewNetwork(0.5, 1, layers, RandomWeights(1, layers, 1)); return network}
+	tested := 0
+	for _, fold := range folds {
+		if len(fold.Test.TrainingSets) > 0 {tested++}
+	}
+	if tested == len(folds) {
+		t.Log("Failure - Regimen stratified folds did not leave a fold empty")
+		t.Fail()
+		return
+	}
+	if validation := CrossValidate(folds, build, 1); len(validation.Folds) != tested {
+		t.Log("Failure - Cross validation scored an empty fold")
+		t.Log(len(validation.Folds), tested)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Regimen folds reject invalid counts and empty folds are not scored")
+}
+
+func Test_CrossValidate_Workflow (t *testing.T) {
+	resultchan := make(chan []float64)
+	var timeout time.Duration = 1000
+	layers := []Layer {{Neurons: 1, Activation: Activation {Function: Sigmoid, Derivative: SigmoidDerivative}}}
+	build := func () *Network {network, _ := NewNetwork(0.5, 1, layers, RandomWeights(1, layers, 1)); return network}
+	folds, _ := labelled(9, 2).Folds(3, 1)
+	var validation CrossValidation
+
+	go func() {
+		validation = CrossValidate(folds, build, 2)
+		var losses []float64
+		for _, fold := range folds {
+			network := build()
+			network.Train(fold.Train, 2)
+			losses = append(losses, network.Loss(fold.Test))
+			network.Close()
+		}
+		resultchan <- losses
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Cross validation timed out")
+		t.Fail()
+		return
+	case losses := <- resultchan:
+		tested := map[float64]bool {}
+		for _, fold := range folds {
+			if len(fold.Test.TrainingSets) != 3 || len(fold.Train.TrainingSets) != 6 {
+				t.Log("Failure - Cross validation fold sizes inaccurate")
+				t.Fail()
+				return
+			}
+			trained := map[float64]bool {}
+			for _, set := range fold.Train.TrainingSets {
+				trained[set.Input[0]] = true
+			}
+			for _, set := range fold.Test.TrainingSets {
+				if tested[set.Input[0]] || trained[set.Input[0]] {
+					t.Log("Failure - Cross validation folds overlap")
+					t.Fail()
+					return
+				}
+				tested[set.Input[0]] = true
+			}
+		}
+		if len(validation.Folds) != len(losses) {
+			t.Log("Failure - Cross validation metrics incomplete")
+			t.Fail()
+			return
+		}
+		var mean, variance float64
+		for k, loss := range losses {
+			if math.Abs(validation.Folds[k]["loss"] - loss) > 1e-9 {
+				t.Log("Failure - Cross validation fold loss differs from a fresh network")
+				t.Log(validation.Folds[k]["loss"], loss)
+				t.Fail()
+				return
+			}
+			mean = mean + loss / float64(len(losses))
+		}
+		for _, loss := range losses {
+			variance = variance + (loss - mean) * (loss - mean) / float64(len(losses))
+		}
+		if math.Abs(validation.Mean["loss"] - mean) > 1e-9 || math.Abs(validation.StdDev["loss"] - math.Sqrt(variance)) > 1e-9 {
+			t.Log("Failure - Cross validation summary does not match the fold losses")
+			t.Log(validation.Mean, validation.StdDev, losses)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Cross validation workflow is clear")
+		return
+	}
+}
@@ -0,0 +1,322 @@
+package ann
+
+import (
+	"fmt"
+	"time"
+)
+
+type Connection struct {
+	Input int
+	Layer int
+	Row int
+	Column int
+}
+
+type Parameter struct {
+	Read chan float64
+	Checkout chan float64
+	Contribute chan float64
+	Probe Probe
+}
+
+type Tie struct {
+	Layer int
+	Transpose bool
+}
+
+func NewParameter () Parameter {
+	return Parameter {Read: make(chan float64), Checkout: make(chan float64), Contribute: make(chan float64), Probe: Probe {Read: make(chan float64), Write: make(chan float64)}}
+}
+
+func SharedWeight (weight float64, references int, parameter Parameter, cancelchan chan struct{}) {
+	var pending float64
+	checkouts, contributions := 0, 0
+	for {
+		read, checkout, probe := parameter.Read, parameter.Checkout, parameter.Probe
+		if contributions > 0 {read, checkout = nil, nil}
+		if checkouts > 0 || contributions > 0 {probe = Probe {}}
+		select {
+		case read <- weight:
+		case checkout <- weight:
+			checkouts++
+		case contribution := <- parameter.Contribute:
+			pending, contributions = pending + contribution, contributions + 1
+			if contributions < references {continue}
+			weight, pending, checkouts, contributions = weight + pending, 0, 0, 0
+			if devsignal {fmt.Printf("\n%v: Shared weight adjusted [%f]...\n", time.Now(), weight)}
+		case probe.Read <- weight:
+		case weight = <- probe.Write:
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func FixedWeight (weight float64, parameter Parameter, cancelchan chan struct{}) {
+	for {
+		select {
+		case parameter.Read <- weight:
+		case parameter.Checkout <- weight:
+		case <- parameter.Contribute:
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func SharedSynapse (parameter Parameter, tag Tag, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	inputs := map[moment]Signal {}
+	var weight, pending float64
+	for {
+		select {
+		case signal := <- peripherals.Input:
+			if len(inputs) == 0 {
+				read := parameter.Checkout
+				if signal.Infer {read = parameter.Read}
+				ok, current := PullOrCancel(read, cancelchan)
+				if !ok {return}
+				weight = current
+			}
+			output := Signal {Sample: signal.Sample, Step: signal.Step, Source: tag.Source, Infer: signal.Infer, Values: make([]float64, len(signal.Values))}
+			for b, value := range signal.Values {
+				output.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if !signal.Infer {inputs[signal.moment()] = signal}
+		case errormargin := <- peripherals.Upfeed:
+			margin := Signal {Sample: errormargin.Sample, Step: errormargin.Step, Source: tag.Target, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			for b, value := range errormargin.Values {
+				margin.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(margin, peripherals.Downfeed, cancelchan) {return}
+			ok, adjustment := PullSignalOrCancel(peripherals.Upfeed, cancelchan)
+			if !ok {return}
+			input := inputs[errormargin.moment()]
+			delete(inputs, errormargin.moment())
+			for b := 0; b < len(adjustment.Values) && b < len(input.Values); b++ {
+				pending = pending + adjustment.Values[b] * input.Values[b]
+			}
+			if len(inputs) > 0 {continue}
+			if !PushOrCancel(pending, parameter.Contribute, cancelchan) {return}
+			pending = 0
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func Ground (source int, clock chan Tick, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	var recorded []Signal
+	awaiting := 0
+	record := func (signal Signal) {
+		awaiting--
+		if !signal.Infer {recorded = append(recorded, signal)}
+	}
+	for {
+		select {
+		case tick := <- clock:
+			if !tick.Backward {
+				awaiting++
+				continue
+			}
+			for awaiting > 0 {
+				ok, signal := PullSignalOrCancel(peripherals.Input, cancelchan)
+				if !ok {return}
+				record(signal)
+			}
+			for _, signal := range recorded {
+				margin := Signal {Sample: signal.Sample, Step: signal.Step, Source: source, Values: make([]float64, len(signal.Values))}
+				if !PushSignalOrCancel(margin, peripherals.Downfeed, cancelchan) {return}
+			}
+			recorded = nil
+		case signal := <- peripherals.Input:
+			record(signal)
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func (network *Network) ground (junction *junction) {
+	clock := make(chan Tick)
+	lane := make(chan Signal)
+	network.clocks, junction.clocks = append(network.clocks, clock), append(junction.clocks, clock)
+	go Ground (len(junction.lanes), clock, SignalPeripherals {Input: lane, Downfeed: junction.node.Upfeed}, junction.cancelchan)
+	junction.lanes = append(junction.lanes, lane)
+}
+
+func linked (layers []Layer, l int) bool {
+	return layers[l].Convolution.Kernel > 0 || layers[l].Tie != nil || layers[l].Mask != nil
+}
+
+func shared (layers []Layer, l int) bool {
+	if layers[l].Convolution.Kernel > 0 || layers[l].Tie != nil {return true}
+	for _, layer := range layers {
+		if layer.Tie != nil && layer.Tie.Layer == l {return true}
+	}
+	return false
+}
+
+func CheckTies (inputs int, layers []Layer) error {
+	for l, layer := range layers {
+		tie := layer.Tie
+		if tie == nil {continue}
+		if tie.Layer < 0 || tie.Layer >= len(layers) || tie.Layer == l {return fmt.Errorf("ann: layer %d ties to no layer %d", l, tie.Layer)}
+		target := layers[tie.Layer]
+		if target.Tie != nil || target.Convolution.Kernel > 0 || target.Normalization.Kind != "" || target.Embedding.Dimensions > 0 || target.Cell.Name != "" || target.Mask != nil {
+			return fmt.Errorf("ann: layer %d ties to layer %d, which is not a plain dense layer", l, tie.Layer)
+		}
+		fanin, tiedfanin := inputs, inputs
+		if l > 0 {fanin = layers[l - 1].Neurons}
+		if tie.Layer > 0 {tiedfanin = layers[tie.Layer - 1].Neurons}
+		if !tie.Transpose && (layer.Neurons != target.Neurons || fanin != tiedfanin || len(spans(inputs, layers, l)[0]) != len(spans(inputs, layers, tie.Layer)[0])) {
+			return fmt.Errorf("ann: layer %d has %d neurons over %d inputs but tied layer %d has %d neurons over %d inputs", l, layer.Neurons, fanin, tie.Layer, target.Neurons, tiedfanin)
+		}
+		if tie.Transpose && (layer.Neurons != tiedfanin || fanin != target.Neurons) {
+			return fmt.Errorf("ann: layer %d has %d neurons over %d inputs but transposing layer %d needs %d neurons over %d inputs", l, layer.Neurons, fanin, tie.Layer, tiedfanin, target.Neurons)
+		}
+	}
+	return nil
+}
+
+func connections (inputs int, layers []Layer, l int) [][]Connection {
+	if layers[l].Convolution.Kernel > 0 {return convolutionConnections(inputs, layers, l)}
+	links := make([][]Connection, layers[l].Neurons)
+	tie := layers[l].Tie
+	if tie == nil {
+		for j := range links {
+			for _, i := range spans(inputs, layers, l)[0] {
+				if layers[l].Mask != nil && !layers[l].Mask[j][i] {continue}
+				links[j] = append(links[j], Connection {Input: i, Layer: l, Row: j, Column: i})
+			}
+		}
+		return links
+	}
+	fanin := inputs
+	if l > 0 {fanin = layers[l - 1].Neurons}
+	for j := range links {
+		if !tie.Transpose {
+			for _, i := range spans(inputs, layers, tie.Layer)[0] {
+				links[j] = append(links[j], Connection {Input: i, Layer: tie.Layer, Row: j, Column: i})
+			}
+			continue
+		}
+		for i := 0; i < fanin; i++ {
+			links[j] = append(links[j], Connection {Input: i, Layer: tie.Layer, Row: i, Column: j})
+		}
+		links[j] = append(links[j], Connection {Input: fanin, Layer: l, Row: j, Column: 0})
+	}
+	return links
+}
+
+func tiedWeights (layer Layer) [][]float64 {
+	if !layer.Tie.Transpose {return [][]float64 {}}
+	weights := make([][]float64, layer.Neurons)
+	for j := range weights {
+		weights[j] = []float64 {0}
+	}
+	return weights
+}
+
+func (network *Network) share (inputs int, layers []Layer, weights [][][]float64) ([][][]Parameter, Parameter) {
+	references := make([][][]int, len(layers))
+	for l := range layers {
+		if !shared(layers, l) {continue}
+		references[l] = make([][]int, len(weights[l]))
+		for j := range weights[l] {
+			references[l][j] = make([]int, len(weights[l][j]))
+		}
+	}
+	for l := range layers {
+		if !shared(layers, l) {continue}
+		for _, links := range connections(inputs, layers, l) {
+			for _, link := range links {
+				if link.Row >= 0 {references[link.Layer][link.Row][link.Column]++}
+			}
+		}
+	}
+	parameters := make([][][]Parameter, len(layers))
+	for l := range references {
+		if references[l] == nil {continue}
+		parameters[l] = make([][]Parameter, len(weights[l]))
+		for j := range weights[l] {
+			parameters[l][j] = make([]Parameter, len(weights[l][j]))
+			for i, weight := range weights[l][j] {
+				parameters[l][j][i] = NewParameter()
+				go SharedWeight (weight, references[l][j][i], parameters[l][j][i], network.cancelchan)
+			}
+		}
+	}
+	fixed := NewParameter()
+	go FixedWeight (1, fixed, network.cancelchan)
+	return parameters, fixed
+}
+
+func NewSharedLayer (layer Layer, upstream []SignalPeripherals, lanes [][]chan Signal, links [][]Connection, parameters [][][]Parameter, fixed Parameter, cancelchan chan struct{}) ([]SignalPeripherals, [][]chan Signal) {
+	neurons := make([]SignalPeripherals, layer.Neurons)
+	terminals := make([][]chan Signal, layer.Neurons)
+	for j := range neurons {
+		neurons[j] = NewSignalPeripherals()
+		terminals[j] = make([]chan Signal, len(links[j]))
+		for s, link := range links[j] {
+			lane := make(chan Signal)
+			lanes[link.Input] = append(lanes[link.Input], lane)
+			terminals[j][s] = make(chan Signal)
+			parameter := fixed
+			if link.Row >= 0 {parameter = parameters[link.Layer][link.Row][link.Column]}
+			synapse := SignalPeripherals {Input: lane, Output: neurons[j].Input, Upfeed: terminals[j][s], Downfeed: upstream[link.Input].Upfeed}
+			go SharedSynapse (parameter, Tag {Source: s, Target: len(lanes[link.Input]) - 1}, synapse, cancelchan)
+		}
+	}
+	return neurons, terminals
+}
+
+func probing (parameters [][]Parameter) [][]Probe {
+	probes := make([][]Probe, len(parameters))
+	for j := range parameters {
+		probes[j] = make([]Probe, len(parameters[j]))
+		for i, parameter := range parameters[j] {
+			probes[j][i] = parameter.Probe
+		}
+	}
+	return probes
+}
+
+func (matrix *Matrix) weight (link Connection) float64 {
+	if link.Row < 0 {return 1}
+	return matrix.Weights[link.Layer][link.Row][link.Column]
+}
+
+func (matrix *Matrix) link (l int, signals []float64, output []float64) []float64 {
+	layer := matrix.Layers[l]
+	aggregation := aggregator(layer)
+	excitements := make([]float64, layer.Neurons)
+	for j, links := range connections(matrix.Inputs, matrix.Layers, l) {
+		weighted := make([]float64, len(links))
+		for s, link := range links {
+			weighted[s] = signals[link.Input] * matrix.weight(link)
+		}
+		excitements[j] = aggregation.Function(weighted)
+		output[j] = layer.Activation.Function(excitements[j])
+	}
+	return excitements
+}
+
+func (matrix *Matrix) unlink (l int, signals []float64, excitements []float64, errormargins []float64, upstream []float64, adjustments [][][]float64) {
+	layer := matrix.Layers[l]
+	aggregation := aggregator(layer)
+	links := connections(matrix.Inputs, matrix.Layers, l)
+	for j, errormargin := range errormargins {
+		delta := errormargin * layer.Activation.Derivative(excitements[j])
+		adjustment := matrix.LearnRate * delta
+		weighted := make([]float64, len(links[j]))
+		for s, link := range links[j] {
+			weighted[s] = signals[link.Input] * matrix.weight(link)
+		}
+		for s, link := range links[j] {
+			partial := aggregation.Derivative(weighted, s)
+			upstream[link.Input] = upstream[link.Input] + delta * partial * matrix.weight(link)
+			if link.Row >= 0 {adjustments[link.Layer][link.Row][link.Column] = adjustments[link.Layer][link.Row][link.Column] + adjustment * partial * signals[link.Input]}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_SharedWeight_Single_Update (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 50
+	cancelchan := make(chan struct{})
+	defer close(cancelchan)
+	parameter := NewParameter()
+
+	go SharedWeight (0.5, 8, parameter, cancelchan)
+	go func() {
+		for r := 0; r < 8; r++ {
+			go func () {parameter.Contribute <- 0.125}()
+		}
+		for r := 0; r < 8; r++ {
+			if weight := <- parameter.Read; weight != 0.5 && weight != 1.5 {
+				resultchan <- weight
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+		resultchan <- <- parameter.Probe.Read
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Shared weight stalled on concurrent contributions")
+		t.Fail()
+		return
+	case weight := <- resultchan:
+		if weight != 1.5 {
+			t.Log("Failure - Shared weight applied a partial update")
+			t.Log(weight)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Shared weight applies concurrent contributions in one update")
+		return
+	}
+}
+
+func tiedLayers () []Layer {
+	return []Layer {
+		{Neurons: 4, Activation: Activations["sigmoid"]},
+		{Neurons: 4, Activation: Activations["sigmoid"], Tie: &Tie {Layer: 0}},
+		{Neurons: 3, Activation: Activations["sigmoid"]},
+		{Neurons: 4, Activation: Activations["sigmoid"], Tie: &Tie {Layer: 2, Transpose: true}},
+	}
+}
+
+func Test_Matrix_Tied_Gradients (t *testing.T) {
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 4, Activation: Activations["sigmoid"], Tie: &Tie {Layer: 0, Transpose: true}}}
+	weights := RandomWeights(4, layers, 5)
+	weights[1][2][0] = 0.3
+	set := TrainingSet {Input: []float64 {0.1, 0.9, 0.4, 0.7}, Expect: []float64 {0.1, 0.9, 0.4, 0.7}}
+	matrix := NewMatrix(0.1, 4, layers, weights)
+	matrix.Learn(set)
+	loss := func (weights [][][]float64) float64 {
+		return SquaredError(set.Expect, NewMatrix(0.1, 4, layers, weights).Predict(set.Input))
+	}
+	for l := range weights {
+		for j := range weights[l] {
+			for i, weight := range weights[l][j] {
+				shifted := copyWeights(weights)
+				shifted[l][j][i] = weight + 1e-6
+				upper := loss(shifted)
+				shifted[l][j][i] = weight - 1e-6
+				lower := loss(shifted)
+				numeric := (upper - lower) / 2e-6
+				analytic := (weight - matrix.Weights[l][j][i]) / matrix.LearnRate
+				if math.Abs(numeric - analytic) > 1e-6 {
+					t.Log("Failure - Tied weight gradients disagree with finite differences")
+					t.Log(l, j, i, analytic, numeric)
+					t.Fail()
+					return
+				}
+			}
+		}
+	}
+	t.Log("Success - Tied weight gradients agree with finite differences")
+}
+
+func Test_Network_Tied_Matrix (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 1000
+	layers := tiedLayers()
+	weights := RandomWeights(4, layers, 23)
+	network, _ := NewNetwork(0.5, 4, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 4, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {1, 0, 0, 1}, Expect: []float64 {1, 0, 0, 1}},
+		{Input: []float64 {0, 1, 1, 0}, Expect: []float64 {0, 1, 1, 0}},
+	}}
+
+	go func() {
+		var divergences []Divergence
+		for epoch := 0; epoch < 5; epoch++ {
+			divergences = append(divergences, CrossCheck(network, matrix, regimen)...)
+			expected := matrix.LearnBatch(regimen.TrainingSets)
+			for s, output := range network.LearnBatch(regimen.TrainingSets) {
+				for j := range output {
+					divergences = append(divergences, Divergence {Sample: s, Output: math.Abs(output[j] - expected[s][j])})
+				}
+			}
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Tied network cross check timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Tied network diverged from the matrix engine")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		var buffer bytes.Buffer
+		network.Save(&buffer)
+		model, err := LoadModel(&buffer)
+		if err != nil || model.Layers[3].Tie == nil || *model.Layers[3].Tie != *layers[3].Tie || len(model.Weights[1]) != 0 || len(model.Weights[3][0]) != 1 {
+			t.Log("Failure - Tied weights lost in the saved model")
+			t.Log(err)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Tied network matches the matrix engine")
+		return
+	}
+}
+
+func Test_CheckTies_Shapes (t *testing.T) {
+	if err := CheckTies(4, tiedLayers()); err != nil {
+		t.Log("Failure - Matching tied layers were rejected")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	mismatches := [][]Layer {
+		{{Neurons: 4}, {Neurons: 3, Tie: &Tie {Layer: 0}}},
+		{{Neurons: 3}, {Neurons: 3, Tie: &Tie {Layer: 0, Transpose: true}}},
+		{{Neurons: 3}, {Neurons: 4, Tie: &Tie {Layer: 0, Transpose: true}}, {Neurons: 4, Tie: &Tie {Layer: 1}}},
+		{{Neurons: 4}, {Neurons: 4, Tie: &Tie {Layer: 2}}},
+	}
+	for m, layers := range mismatches {
+		for l := range layers {
+			layers[l].Activation = Activations["sigmoid"]
+		}
+		network, err := NewNetwork(0.5, 4, layers, RandomWeights(4, layers, 1))
+		if err == nil || network != nil {
+			t.Log("Failure - Mismatched tie was wired")
+			t.Log(m)
+			t.Fail()
+			return
+		}
+	}
+	t.Log("Success - Tie shapes are checked before wiring")
+}
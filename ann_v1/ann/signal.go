@@ -0,0 +1,218 @@
+package ann
+
+import (
+	"fmt"
+	"time"
+)
+
+const devsignal bool = false
+
+type Signal struct {
+	Sample int
+	Step int
+	Source int
+	Infer bool
+	Values []float64
+}
+
+type Tag struct {
+	Source int
+	Target int
+}
+
+type moment struct {
+	sample int
+	step int
+}
+
+type Fault struct {
+	Kind string
+	Sample int
+	Step int
+	Source int
+	Missing []int
+}
+
+var DendriteTimeout time.Duration = time.Second
+
+type SignalPeripherals struct {
+	Input chan Signal
+	Output chan Signal
+	Upfeed chan Signal
+	Downfeed chan Signal
+}
+
+func (signal Signal) moment () moment {
+	return moment {sample: signal.Sample, step: signal.Step}
+}
+
+func NewSignal (size int) Signal {
+	return Signal {Values: make([]float64, size)}
+}
+
+func Scalar (value float64) Signal {
+	return Signal {Values: []float64 {value}}
+}
+
+func Fill (size int, value float64) Signal {
+	signal := NewSignal(size)
+	for b := range signal.Values {
+		signal.Values[b] = value
+	}
+	return signal
+}
+
+func NewSignalPeripherals () SignalPeripherals {
+	return SignalPeripherals {Input: make(chan Signal), Output: make(chan Signal), Upfeed: make(chan Signal), Downfeed: make(chan Signal)}
+}
+
+func PushSignalOrCancel (signal Signal, outchan chan Signal, cancelchan chan struct{}) bool {
+	select {
+	case outchan <- signal:
+		return true
+	case <- cancelchan:
+		return false
+	}
+}
+
+func PullSignalOrCancel (inchan chan Signal, cancelchan chan struct{}) (bool, Signal) {
+	select {
+	case signal := <- inchan:
+		return true, signal
+	case <- cancelchan:
+		return false, Signal {}
+	}
+}
+
+func ReportFault (fault Fault, faultchan chan Fault) {
+	select {
+	case faultchan <- fault:
+	default:
+		if devsignal {fmt.Printf("\n%v: Fault dropped %v...\n", time.Now(), fault)}
+	}
+}
+
+func NewSignalNeuron (learnrate float64, synapses Synapses, activation Activation, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	internals := NewSignalPeripherals()
+
+	go SignalNucleus (learnrate, activation, internals, cancelchan)
+	go SignalAxon (synapses.Outgoing, internals.Output, peripherals.Output, cancelchan)
+	go SignalDendrite (synapses.Ingoing, peripherals.Input, internals.Input, cancelchan)
+	go SignalAxon (synapses.Ingoing, internals.Downfeed, peripherals.Downfeed, cancelchan)
+	go SignalDendrite (synapses.Outgoing, peripherals.Upfeed, internals.Upfeed, cancelchan)
+	if devsignal {fmt.Printf("\n%v: Signal neuron initialized...\n", time.Now())}
+}
+
+func SignalNucleus (learnrate float64, activation Activation, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	excitements := map[moment]Signal {}
+	for {
+		select {
+		case input := <- peripherals.Input:
+			if !input.Infer {excitements[input.moment()] = input}
+			output := Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(input.Values))}
+			for b, value := range input.Values {
+				output.Values[b] = activation.Function(value)
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if devsignal {fmt.Printf("\n%v: Nucleus output %v...\n", time.Now(), output.Values)}
+		case errormargin := <- peripherals.Upfeed:
+			excitement := excitements[errormargin.moment()]
+			delete(excitements, errormargin.moment())
+			delta := Signal {Sample: errormargin.Sample, Step: errormargin.Step, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			adjustment := Signal {Sample: errormargin.Sample, Step: errormargin.Step, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			for b, value := range errormargin.Values {
+				var net float64
+				if b < len(excitement.Values) {net = excitement.Values[b]}
+				delta.Values[b] = value * activation.Derivative(net)
+				adjustment.Values[b] = learnrate * delta.Values[b]
+			}
+			if !PushSignalOrCancel(delta, peripherals.Downfeed, cancelchan) {return}
+			if !PushSignalOrCancel(adjustment, peripherals.Downfeed, cancelchan) {return}
+			if devsignal {fmt.Printf("\n%v: Nucleus adjustment %v...\n", time.Now(), adjustment.Values)}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func SignalDendrite (signals int, inputchan chan Signal, outputchan chan Signal, cancelchan chan struct{}) {
+	signalcounts := map[moment]int {}
+	sums := map[moment]Signal {}
+	for {
+		select {
+		case input := <- inputchan:
+			sum, ok := sums[input.moment()]
+			if !ok {sum = Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(input.Values))}}
+			signalcounts[input.moment()]++
+			for b, value := range input.Values {
+				sum.Values[b] = sum.Values[b] + value
+			}
+			sums[input.moment()] = sum
+			if signalcounts[input.moment()] == signals {
+				delete(sums, input.moment()); delete(signalcounts, input.moment())
+				if devsignal {fmt.Printf("\n%v: Dendrite relayed sample %d %v...\n", time.Now(), sum.Sample, sum.Values)}
+				if !PushSignalOrCancel(sum, outputchan, cancelchan) {return}
+			}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func TaggedDendrite (signals int, inputchan chan Signal, outputchan chan Signal, faultchan chan Fault, cancelchan chan struct{}) {
+	AggregateDendrite(signals, Aggregations["sum"], inputchan, outputchan, nil, faultchan, cancelchan)
+}
+
+func SignalAxon (signals int, inputchan chan Signal, outputchan chan Signal, cancelchan chan struct{}) {
+	for {
+		select {
+		case input := <- inputchan:
+			for i := 0; i < signals; i++ {
+				if !PushSignalOrCancel(input, outputchan, cancelchan) {return}
+			}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func SignalSynapse (weight float64, peripherals SignalPeripherals, probe Probe, cancelchan chan struct{}) {
+	TaggedSynapse(weight, Tag {}, peripherals, probe, cancelchan)
+}
+
+func TaggedSynapse (weight float64, tag Tag, peripherals SignalPeripherals, probe Probe, cancelchan chan struct{}) {
+	inputs := map[moment]Signal {}
+	var pending float64
+	for {
+		select {
+		case signal := <- peripherals.Input:
+			output := Signal {Sample: signal.Sample, Step: signal.Step, Source: tag.Source, Infer: signal.Infer, Values: make([]float64, len(signal.Values))}
+			for b, value := range signal.Values {
+				output.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			if devsignal {fmt.Printf("\n%v: Synapse relayed sample %d %v...\n", time.Now(), output.Sample, output.Values)}
+			if !signal.Infer {inputs[signal.moment()] = signal}
+		case errormargin := <- peripherals.Upfeed:
+			margin := Signal {Sample: errormargin.Sample, Step: errormargin.Step, Source: tag.Target, Infer: errormargin.Infer, Values: make([]float64, len(errormargin.Values))}
+			for b, value := range errormargin.Values {
+				margin.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(margin, peripherals.Downfeed, cancelchan) {return}
+			ok, adjustment := PullSignalOrCancel(peripherals.Upfeed, cancelchan)
+			if !ok {return}
+			input := inputs[errormargin.moment()]
+			delete(inputs, errormargin.moment())
+			for b := 0; b < len(adjustment.Values) && b < len(input.Values); b++ {
+				pending = pending + adjustment.Values[b] * input.Values[b]
+			}
+			if len(inputs) > 0 {continue}
+			weight, pending = weight + pending, 0
+			if devsignal {fmt.Printf("\n%v: Synapse adjusted [%f]...\n", time.Now(), weight)}
+		case probe.Read <- weight:
+		case weight = <- probe.Write:
+		case <- cancelchan:
+			return
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_SignalSynapse_Batch_Weighting (t *testing.T) {
+	internals := NewSignalPeripherals()
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 5
+
+	go SignalSynapse (0.3, internals, Probe {}, cancelchan)
+	go func() {
+		internals.Input <- Signal {Values: []float64 {1246, 10}}
+		result := <- internals.Output
+		if result.Values[0] != 373.8 || result.Values[1] != 3 {
+			t.Log("Failure - Signal synapse batch weighting inaccurate")
+			t.Log(result.Values)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Signal synapse weighting timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Signal synapse batch weighting is accurate")
+		return
+	}
+}
+
+func Test_SignalSynapse_Batch_WeightChange (t *testing.T) {
+	internals := NewSignalPeripherals()
+	probe := Probe {Read: make(chan float64), Write: make(chan float64)}
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 5
+
+	go SignalSynapse (1, internals, probe, cancelchan)
+	go func() {
+		internals.Input <- Signal {Values: []float64 {1, 2}}; <- internals.Output
+		internals.Upfeed <- NewSignal(2); <- internals.Downfeed; internals.Upfeed <- Signal {Values: []float64 {-0.3, 0.1}}
+		if weight := <- probe.Read; math.Abs(weight - 0.9) > 1e-12 {
+			t.Log("Failure - Signal synapse batch weight change inaccurate")
+			t.Log(weight)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Signal synapse weight change timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Signal synapse batch weight change is accurate")
+		return
+	}
+}
+
+func Test_SignalSynapse_Input_Pipelining (t *testing.T) {
+	internals := NewSignalPeripherals()
+	cancelchan := make(chan struct{})
+	resultchan := make(chan struct{})
+	var startweight float64 = 1
+	var timeout time.Duration = 10
+
+	go SignalSynapse (startweight, internals, Probe {}, cancelchan)
+	go func() {
+		internals.Input <- Signal {Sample: 0, Values: []float64 {1}}; <- internals.Output
+		internals.Input <- Signal {Sample: 1, Values: []float64 {1}}; <- internals.Output
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Signal synapse input blocked the next sample")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Signal synapse input is pipelined across samples")
+		return
+	}
+}
+
+func Test_SignalDendrite_Batch_Summation (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 5
+	inputchan := make(chan Signal); outputchan := make(chan Signal)
+
+	go SignalDendrite(3, inputchan, outputchan, cancelchan)
+	go func() {
+		inputchan <- Signal {Values: []float64 {1, 2}}; inputchan <- Signal {Values: []float64 {1, 2}}; inputchan <- Signal {Values: []float64 {1, 2}}
+		if result := <- outputchan; result.Values[0] != 3 || result.Values[1] != 6 {
+			t.Log("Failure - Signal dendrite summation inaccurate")
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Signal dendrite summation timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Signal dendrite summation is accurate")
+		return
+	}
+}
+
+func Test_SignalNeuron_Scalar_Equivalence (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 10
+	activation := Activation {Function: Sigmoid, Derivative: SigmoidDerivative}
+	neuron := NewSignalPeripherals()
+	synapses := Synapses {Ingoing: 1, Outgoing: 1}; learningrate := 0.5
+
+	NewSignalNeuron(learningrate, synapses, activation, neuron, cancelchan)
+	go func() {
+		neuron.Input <- Scalar(1); <- neuron.Output
+		neuron.Upfeed <- Scalar(0.5); <- neuron.Downfeed; result := <- neuron.Downfeed
+		if result.Values[0] != 0.04915298331037046 {
+			t.Log("Failure - Signal neuron differs from the scalar neuron")
+			t.Log(result.Values)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Signal neuron equivalence timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Signal neuron of batch size 1 matches the scalar neuron")
+		return
+	}
+}
+
+func Test_Network_LearnBatch_Matrix (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 4, Activation: Activations["sigmoid"]}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 23)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {0, 0}, Expect: []float64 {0}},
+		{Input: []float64 {0, 1}, Expect: []float64 {1}},
+		{Input: []float64 {1, 0}, Expect: []float64 {1}},
+		{Input: []float64 {1, 1}, Expect: []float64 {0}},
+	}}
+
+	go func() {
+		network.TrainBatches(regimen, 10, 4)
+		matrix.TrainBatches(regimen, 10, 4)
+		var divergence float64
+		after := network.Weights()
+		for l := range after {
+			for j := range after[l] {
+				for i := range after[l][j] {
+					divergence = math.Max(divergence, math.Abs(after[l][j][i] - matrix.Weights[l][j][i]))
+				}
+			}
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network batch learning timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-9 {
+			t.Log("Failure - Network batch learning diverged from the matrix engine")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Network batch learning matches the matrix engine")
+		return
+	}
+}
+
+func Test_Network_Forward_Release (t *testing.T) {
+	resultchan := make(chan [2]float64)
+	var timeout time.Duration = 200
+	layers := []Layer {{Neurons: 2, Activation: Activations["sigmoid"], Rules: [][]Rule {{Rules["hebbian"], {}, {}}, {{}, Rules["oja"], {}}}}, {Neurons: 1, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 29)
+	forwarded, _ := NewNetwork(0.5, 2, layers, weights)
+	defer forwarded.Close()
+	trained, _ := NewNetwork(0.5, 2, layers, weights)
+	defer trained.Close()
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {0, 1}, Expect: []float64 {1}},
+		{Input: []float64 {1, 0}, Expect: []float64 {0}},
+	}}
+
+	go func() {
+		var drift, divergence float64
+		for _, set := range regimen.TrainingSets {
+			forwarded.Forward(set.Input)
+			forwarded.ForwardBatch([][]float64 {set.Input, set.Input})
+		}
+		before := forwarded.Weights()
+		forwarded.TrainBatches(regimen, 5, 2)
+		trained.TrainBatches(regimen, 5, 2)
+		after, expected := forwarded.Weights(), trained.Weights()
+		for l := range after {
+			for j := range after[l] {
+				for i := range after[l][j] {
+					drift = math.Max(drift, math.Abs(before[l][j][i] - weights[l][j][i]))
+					divergence = math.Max(divergence, math.Abs(after[l][j][i] - expected[l][j][i]))
+				}
+			}
+		}
+		resultchan <- [2]float64 {drift, divergence}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Forward passes without feedback stalled the network")
+		t.Fail()
+		return
+	case result := <- resultchan:
+		if result[0] != 0 {
+			t.Log("Failure - Forward passes without feedback changed the weights")
+			t.Log(result[0])
+			t.Fail()
+			return
+		}
+		if result[1] > 1e-9 {
+			t.Log("Failure - Forward passes without feedback changed later learning")
+			t.Log(result[1])
+			t.Fail()
+			return
+		}
+		t.Log("Success - Forward passes without feedback release their samples")
+		return
+	}
+}
+
+func Test_TaggedDendrite_Duplicate_Rejection (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 5
+	inputchan := make(chan Signal); outputchan := make(chan Signal); faultchan := make(chan Fault, 1)
+
+	go TaggedDendrite(2, inputchan, outputchan, faultchan, cancelchan)
+	go func() {
+		inputchan <- Signal {Sample: 7, Source: 0, Values: []float64 {1}}; inputchan <- Signal {Sample: 7, Source: 0, Values: []float64 {1}}
+		if fault := <- faultchan; fault.Kind != "duplicate" || fault.Sample != 7 || fault.Source != 0 {
+			t.Log("Failure - Tagged dendrite duplicate fault inaccurate")
+			t.Log(fault)
+			return
+		}
+		inputchan <- Signal {Sample: 7, Source: 1, Values: []float64 {2}}
+		if result := <- outputchan; result.Sample != 7 || result.Values[0] != 3 {
+			t.Log("Failure - Tagged dendrite summed the duplicate")
+			t.Log(result)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Tagged dendrite duplicate rejection timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Tagged dendrite rejects duplicate sources")
+		return
+	}
+}
+
+func Test_TaggedDendrite_Missing_Report (t *testing.T) {
+	cancelchan := make(chan struct{}); resultchan := make(chan struct{})
+	var timeout time.Duration = 50
+	inputchan := make(chan Signal); outputchan := make(chan Signal); faultchan := make(chan Fault, 1)
+	defer func (restore time.Duration) {DendriteTimeout = restore}(DendriteTimeout)
+	DendriteTimeout = 5 * time.Millisecond
+
+	go TaggedDendrite(3, inputchan, outputchan, faultchan, cancelchan)
+	go func() {
+		inputchan <- Signal {Sample: 1, Source: 1, Values: []float64 {1}}
+		if fault := <- faultchan; fault.Kind != "missing" || fault.Sample != 1 || len(fault.Missing) != 2 || fault.Missing[0] != 0 || fault.Missing[1] != 2 {
+			t.Log("Failure - Tagged dendrite missing fault inaccurate")
+			t.Log(fault)
+			return
+		}
+		resultchan <- struct{}{}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Tagged dendrite missing report timed out")
+		t.Fail()
+		return
+	case <- resultchan:
+		t.Log("Success - Tagged dendrite reports missing sources")
+		close(cancelchan)
+		return
+	}
+}
@@ -0,0 +1,232 @@
+package ann
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+const devsom bool = false
+
+type Kohonen struct {
+	Rows int
+	Columns int
+	LearnRate float64
+	FinalRate float64
+	Radius float64
+	FinalRadius float64
+	Schedule string
+}
+
+type SOM struct {
+	Kohonen Kohonen
+	Inputs int
+	source chan Signal
+	broadcast chan Signal
+	winners chan Signal
+	acks chan Signal
+	tables []Table
+	cancelchan chan struct{}
+}
+
+func (kohonen Kohonen) defaults () Kohonen {
+	if kohonen.LearnRate <= 0 {kohonen.LearnRate = 0.5}
+	if kohonen.FinalRate <= 0 {kohonen.FinalRate = 0.01}
+	if kohonen.Radius <= 0 {kohonen.Radius = math.Max(1, math.Max(float64(kohonen.Rows), float64(kohonen.Columns)) / 2)}
+	if kohonen.FinalRadius <= 0 {kohonen.FinalRadius = 0.5}
+	return kohonen
+}
+
+func (kohonen Kohonen) schedule (start float64, end float64, progress float64) float64 {
+	if kohonen.Schedule == "linear" {return start + (end - start) * progress}
+	return start * math.Pow(end / start, progress)
+}
+
+func (kohonen Kohonen) RandomWeights (inputs int, seed int64) [][]float64 {
+	random := rand.New(rand.NewSource(seed))
+	weights := make([][]float64, kohonen.Rows * kohonen.Columns)
+	for u := range weights {
+		weights[u] = make([]float64, inputs)
+		for i := range weights[u] {
+			weights[u][i] = random.Float64()
+		}
+	}
+	return weights
+}
+
+func distance (first []float64, second []float64) float64 {
+	var sum float64
+	for i := range first {
+		sum = sum + (first[i] - second[i]) * (first[i] - second[i])
+	}
+	return sum
+}
+
+func KohonenUnit (kohonen Kohonen, unit int, weights []float64, peripherals SignalPeripherals, table Table, cancelchan chan struct{}) {
+	inputs := map[moment]Signal {}
+	weights = append([]float64 {}, weights...)
+	row, column := unit / kohonen.Columns, unit % kohonen.Columns
+	for {
+		select {
+		case input := <- peripherals.Input:
+			if !input.Infer {inputs[input.moment()] = input}
+			if !PushSignalOrCancel(Signal {Sample: input.Sample, Step: input.Step, Source: unit, Infer: input.Infer, Values: []float64 {distance(input.Values, weights)}}, peripherals.Output, cancelchan) {return}
+		case update := <- peripherals.Upfeed:
+			input := inputs[update.moment()]
+			delete(inputs, update.moment())
+			rows, columns := float64(row - update.Source / kohonen.Columns), float64(column - update.Source % kohonen.Columns)
+			rate, radius := update.Values[0], update.Values[1]
+			neighborhood := math.Exp(-(rows * rows + columns * columns) / (2 * radius * radius))
+			for i := range weights {
+				weights[i] = weights[i] + rate * neighborhood * (input.Values[i] - weights[i])
+			}
+			if !PushSignalOrCancel(Signal {Sample: update.Sample, Step: update.Step, Source: unit}, peripherals.Downfeed, cancelchan) {return}
+		case table.Read <- [][]float64 {append([]float64 {}, weights...)}:
+		case written := <- table.Write:
+			weights = append([]float64 {}, written[0]...)
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func Competition (units int, inputchan chan Signal, outputchan chan Signal, cancelchan chan struct{}) {
+	winners := map[moment]Signal {}
+	counts := map[moment]int {}
+	for {
+		select {
+		case input := <- inputchan:
+			winner, ok := winners[input.moment()]
+			if !ok || input.Values[0] < winner.Values[0] || (input.Values[0] == winner.Values[0] && input.Source < winner.Source) {winners[input.moment()] = input}
+			counts[input.moment()]++
+			if counts[input.moment()] < units {continue}
+			winner = winners[input.moment()]
+			delete(winners, input.moment()); delete(counts, input.moment())
+			if devsom {fmt.Printf("\n%v: Unit %d won step %d...\n", time.Now(), winner.Source, winner.Step)}
+			if !PushSignalOrCancel(winner, outputchan, cancelchan) {return}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func CheckSOM (kohonen Kohonen, inputs int, weights [][]float64) error {
+	if kohonen.Rows <= 0 || kohonen.Columns <= 0 {return fmt.Errorf("ann: Kohonen grid of %d rows and %d columns has no units", kohonen.Rows, kohonen.Columns)}
+	if len(weights) != kohonen.Rows * kohonen.Columns {
+		return fmt.Errorf("ann: Kohonen grid of %dx%d units has %d weight vectors", kohonen.Rows, kohonen.Columns, len(weights))
+	}
+	for u := range weights {
+		if len(weights[u]) != inputs {return fmt.Errorf("ann: Kohonen unit %d has %d weights but needs %d", u, len(weights[u]), inputs)}
+	}
+	return nil
+}
+
+func NewSOM (kohonen Kohonen, inputs int, weights [][]float64) (*SOM, error) {
+	if err := CheckSOM(kohonen, inputs, weights); err != nil {return nil, err}
+	kohonen = kohonen.defaults()
+	som := &SOM {Kohonen: kohonen, Inputs: inputs, source: make(chan Signal), broadcast: make(chan Signal), winners: make(chan Signal), acks: make(chan Signal), cancelchan: make(chan struct{})}
+	distances := make(chan Signal)
+	lanes := make([]chan Signal, len(weights))
+	updates := make([]chan Signal, len(weights))
+	for u := range weights {
+		lanes[u], updates[u] = make(chan Signal), make(chan Signal)
+		table := NewTable()
+		som.tables = append(som.tables, table)
+		go KohonenUnit (kohonen, u, weights[u], SignalPeripherals {Input: lanes[u], Output: distances, Upfeed: updates[u], Downfeed: som.acks}, table, som.cancelchan)
+	}
+	go Terminals (som.source, lanes, som.cancelchan)
+	go Terminals (som.broadcast, updates, som.cancelchan)
+	go Competition (len(weights), distances, som.winners, som.cancelchan)
+	return som, nil
+}
+
+func (som *SOM) compete (input []float64, step int, infer bool) Signal {
+	som.source <- Signal {Step: step, Infer: infer, Values: input}
+	return <- som.winners
+}
+
+func (som *SOM) BestMatch (input []float64) (int, int) {
+	winner := som.compete(input, 0, true)
+	return winner.Source / som.Kohonen.Columns, winner.Source % som.Kohonen.Columns
+}
+
+func (som *SOM) Train (regimen Regimen, epochs int) {
+	total := float64(epochs * len(regimen.TrainingSets))
+	step := 0
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, set := range regimen.TrainingSets {
+			progress := float64(step) / total
+			winner := som.compete(set.Input, step, false)
+			rate := som.Kohonen.schedule(som.Kohonen.LearnRate, som.Kohonen.FinalRate, progress)
+			radius := som.Kohonen.schedule(som.Kohonen.Radius, som.Kohonen.FinalRadius, progress)
+			som.broadcast <- Signal {Step: step, Source: winner.Source, Values: []float64 {rate, radius}}
+			for range som.tables {
+				<- som.acks
+			}
+			step++
+		}
+	}
+}
+
+func (som *SOM) QuantizationError (regimen Regimen) float64 {
+	var sum float64
+	for _, set := range regimen.TrainingSets {
+		sum = sum + math.Sqrt(som.compete(set.Input, 0, true).Values[0])
+	}
+	return sum / float64(len(regimen.TrainingSets))
+}
+
+func (som *SOM) Weights () [][]float64 {
+	weights := make([][]float64, len(som.tables))
+	for u, table := range som.tables {
+		weights[u] = (<- table.Read)[0]
+	}
+	return weights
+}
+
+func (som *SOM) SetWeights (weights [][]float64) {
+	for u, table := range som.tables {
+		table.Write <- [][]float64 {weights[u]}
+	}
+}
+
+func (som *SOM) UMatrix () [][]float64 {
+	weights := som.Weights()
+	umatrix := make([][]float64, som.Kohonen.Rows)
+	for r := range umatrix {
+		umatrix[r] = make([]float64, som.Kohonen.Columns)
+		for c := range umatrix[r] {
+			var sum float64
+			var neighbors int
+			for _, offset := range [][2]int {{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nr, nc := r + offset[0], c + offset[1]
+				if nr < 0 || nr >= som.Kohonen.Rows || nc < 0 || nc >= som.Kohonen.Columns {continue}
+				sum = sum + math.Sqrt(distance(weights[r * som.Kohonen.Columns + c], weights[nr * som.Kohonen.Columns + nc]))
+				neighbors++
+			}
+			if neighbors > 0 {umatrix[r][c] = sum / float64(neighbors)}
+		}
+	}
+	return umatrix
+}
+
+func (som *SOM) ExportUMatrix (writer io.Writer) error {
+	records := csv.NewWriter(writer)
+	for _, row := range som.UMatrix() {
+		record := make([]string, len(row))
+		for c, value := range row {
+			record[c] = strconv.FormatFloat(value, 'g', -1, 64)
+		}
+		if err := records.Write(record); err != nil {return err}
+	}
+	records.Flush()
+	return records.Error()
+}
+
+func (som *SOM) Close () {
+	close(som.cancelchan)
+}
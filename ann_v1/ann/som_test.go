@@ -0,0 +1,127 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func Test_SOM_BestMatch_UMatrix (t *testing.T) {
+	resultchan := make(chan [][]float64)
+	var timeout time.Duration = 1000
+	som, _ := NewSOM(Kohonen {Rows: 1, Columns: 3, LearnRate: 0.5, Radius: 1}, 1, [][]float64 {{0}, {0.5}, {1}})
+	defer som.Close()
+	var row, column int
+	var exported bytes.Buffer
+
+	go func() {
+		row, column = som.BestMatch([]float64 {0.4})
+		som.ExportUMatrix(&exported)
+		som.Train(Regimen {TrainingSets: []TrainingSet {{Input: []float64 {0.6}}}}, 1)
+		resultchan <- som.Weights()
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Self-organizing map timed out")
+		t.Fail()
+		return
+	case weights := <- resultchan:
+		if row != 0 || column != 1 || exported.String() != "0.5,0.5,0.5\n" {
+			t.Log("Failure - Best-matching unit or U-matrix is wrong")
+			t.Log(row, column, exported.String())
+			t.Fail()
+			return
+		}
+		neighbor := 0.5 * math.Exp(-0.5)
+		expected := []float64 {neighbor * 0.6, 0.5 + 0.5 * 0.1, 1 + neighbor * (0.6 - 1)}
+		for u := range expected {
+			if math.Abs(weights[u][0] - expected[u]) > 1e-12 {
+				t.Log("Failure - Neighborhood update is wrong")
+				t.Log(weights, expected)
+				t.Fail()
+				return
+			}
+		}
+		t.Log("Success - Self-organizing map finds the winner and pulls its neighborhood")
+		return
+	}
+}
+
+func Test_SOM_Grid_Shape (t *testing.T) {
+	for _, kohonen := range []Kohonen {{Rows: 2, Columns: 2}, {Rows: 4, Columns: 0}, {Rows: 0, Columns: 4}} {
+		if som, err := NewSOM(kohonen, 1, [][]float64 {{0}, {0.5}, {1}}); err == nil {
+			som.Close()
+			t.Log("Failure - Self-organizing map accepted a grid that does not match its units")
+			t.Log(kohonen)
+			t.Fail()
+			return
+		}
+	}
+	if som, err := NewSOM(Kohonen {Rows: 1, Columns: 3}, 2, [][]float64 {{0}, {0.5}, {1}}); err == nil {
+		som.Close()
+		t.Log("Failure - Self-organizing map accepted units of the wrong width")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Self-organizing map rejects grids and units of the wrong shape")
+}
+
+func Test_SOM_Clustering (t *testing.T) {
+	resultchan := make(chan [][]float64)
+	var timeout time.Duration = 5000
+	random := rand.New(rand.NewSource(8))
+	centers := [][]float64 {{0.1, 0.1}, {0.1, 0.9}, {0.9, 0.1}, {0.9, 0.9}}
+	var regimen Regimen
+	for s := 0; s < 200; s++ {
+		center := centers[s % len(centers)]
+		regimen.TrainingSets = append(regimen.TrainingSets, TrainingSet {Input: []float64 {center[0] + random.NormFloat64() * 0.03, center[1] + random.NormFloat64() * 0.03}})
+	}
+	kohonen := Kohonen {Rows: 6, Columns: 6, Schedule: "linear"}
+	som, _ := NewSOM(kohonen, 2, kohonen.RandomWeights(2, 3))
+	defer som.Close()
+	var before, after float64
+	winners := map[[2]int]int {}
+
+	go func() {
+		before = som.QuantizationError(regimen)
+		som.Train(regimen, 10)
+		after = som.QuantizationError(regimen)
+		for c, center := range centers {
+			row, column := som.BestMatch(center)
+			winners[[2]int {row, column}] = c
+		}
+		resultchan <- som.UMatrix()
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Self-organizing map training timed out")
+		t.Fail()
+		return
+	case umatrix := <- resultchan:
+		if after > 0.05 || after >= before || len(winners) != len(centers) {
+			t.Log("Failure - Self-organizing map did not separate the clusters")
+			t.Log(before, after, winners)
+			t.Fail()
+			return
+		}
+		var peak, sum float64
+		for r := range umatrix {
+			for c := range umatrix[r] {
+				peak, sum = math.Max(peak, umatrix[r][c]), sum + umatrix[r][c]
+			}
+		}
+		if peak < 2 * sum / 36 {
+			t.Log("Failure - U-matrix shows no cluster borders")
+			t.Log(umatrix)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Self-organizing map clusters the inputs")
+		t.Log(before, after)
+		return
+	}
+}
@@ -0,0 +1,272 @@
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const devspiking bool = false
+
+type Spiking struct {
+	Threshold float64
+	Leak float64
+	Reset float64
+	Refractory int
+	Potentiation float64
+	Depression float64
+	Trace float64
+	Bound float64
+}
+
+type SpikingNetwork struct {
+	Spiking Spiking
+	Inputs int
+	sources []SignalPeripherals
+	outputs []chan Signal
+	probes [][][]Probe
+	faults chan Fault
+	cancelchan chan struct{}
+}
+
+func NewSpiking () Spiking {
+	return Spiking {Threshold: 1, Leak: 0.9, Potentiation: 0.01, Depression: 0.012, Trace: 0.9, Bound: 1}
+}
+
+func (spiking Spiking) defaults () Spiking {
+	if spiking.Threshold <= 0 {spiking.Threshold = 1}
+	if spiking.Bound <= 0 {spiking.Bound = 1}
+	return spiking
+}
+
+func spike (fired bool) float64 {
+	if fired {return 1}
+	return 0
+}
+
+func (spiking Spiking) integrate (potential float64, refractory int, current float64) (float64, int, bool) {
+	if refractory > 0 {return spiking.Reset, refractory - 1, false}
+	potential = potential * spiking.Leak + current
+	if potential >= spiking.Threshold {return spiking.Reset, spiking.Refractory, true}
+	return potential, 0, false
+}
+
+func (spiking Spiking) clip (weight float64) float64 {
+	return math.Max(0, math.Min(spiking.Bound, weight))
+}
+
+func LeakyNucleus (spiking Spiking, peripherals SignalPeripherals, cancelchan chan struct{}) {
+	var potentials []float64
+	var refractories []int
+	for {
+		select {
+		case input := <- peripherals.Input:
+			if input.Step == 0 || len(potentials) != len(input.Values) {
+				potentials, refractories = make([]float64, len(input.Values)), make([]int, len(input.Values))
+				for b := range potentials {
+					potentials[b] = spiking.Reset
+				}
+			}
+			output := Signal {Sample: input.Sample, Step: input.Step, Infer: input.Infer, Values: make([]float64, len(input.Values))}
+			for b, current := range input.Values {
+				var fired bool
+				potentials[b], refractories[b], fired = spiking.integrate(potentials[b], refractories[b], current)
+				output.Values[b] = spike(fired)
+				if devspiking && fired {fmt.Printf("\n%v: Nucleus fired at step %d...\n", time.Now(), input.Step)}
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func STDPSynapse (spiking Spiking, weight float64, tag Tag, peripherals SignalPeripherals, activitychan chan Signal, probe Probe, cancelchan chan struct{}) {
+	var pretraces, posttraces []float64
+	inputchan, postchan, readchan, writechan := peripherals.Input, chan Signal(nil), probe.Read, probe.Write
+	for {
+		select {
+		case signal := <- inputchan:
+			if signal.Step == 0 || len(pretraces) != len(signal.Values) {
+				pretraces, posttraces = make([]float64, len(signal.Values)), make([]float64, len(signal.Values))
+			}
+			output := Signal {Sample: signal.Sample, Step: signal.Step, Source: tag.Source, Infer: signal.Infer, Values: make([]float64, len(signal.Values))}
+			for b, value := range signal.Values {
+				output.Values[b] = value * weight
+			}
+			if !PushSignalOrCancel(output, peripherals.Output, cancelchan) {return}
+			for b, value := range signal.Values {
+				pretraces[b] = pretraces[b] * spiking.Trace + value
+				if !signal.Infer && value > 0 {weight = spiking.clip(weight - spiking.Depression * posttraces[b])}
+			}
+			inputchan, postchan, readchan, writechan = nil, activitychan, nil, nil
+		case activity := <- postchan:
+			for b := 0; b < len(activity.Values) && b < len(posttraces); b++ {
+				posttraces[b] = posttraces[b] * spiking.Trace + activity.Values[b]
+				if !activity.Infer && activity.Values[b] > 0 {weight = spiking.clip(weight + spiking.Potentiation * pretraces[b])}
+			}
+			inputchan, postchan, readchan, writechan = peripherals.Input, nil, probe.Read, probe.Write
+		case readchan <- weight:
+		case weight = <- writechan:
+		case <- cancelchan:
+			return
+		}
+	}
+}
+
+func NewSpikingNetwork (spiking Spiking, inputs int, weights [][][]float64) *SpikingNetwork {
+	spiking = spiking.defaults()
+	network := &SpikingNetwork {Spiking: spiking, Inputs: inputs, faults: make(chan Fault, 64), cancelchan: make(chan struct{})}
+	upstream := make([]SignalPeripherals, inputs)
+	for i := range upstream {
+		upstream[i] = NewSignalPeripherals()
+	}
+	network.sources = upstream
+	spawn := func (nodes []SignalPeripherals, lanes [][]chan Signal, taps [][]chan Signal) {
+		for i, node := range nodes {
+			if taps == nil {
+				go Terminals (node.Input, lanes[i], network.cancelchan)
+				continue
+			}
+			internals := NewSignalPeripherals()
+			go TaggedDendrite (len(taps[i]), node.Input, internals.Input, network.faults, network.cancelchan)
+			go LeakyNucleus (spiking, internals, network.cancelchan)
+			go Terminals (internals.Output, tap(lanes[i], taps[i], network.cancelchan), network.cancelchan)
+		}
+	}
+	var taps [][]chan Signal
+	for l := range weights {
+		lanes := make([][]chan Signal, len(upstream))
+		neurons := make([]SignalPeripherals, len(weights[l]))
+		probes := make([][]Probe, len(weights[l]))
+		activities := make([][]chan Signal, len(weights[l]))
+		for j := range neurons {
+			neurons[j] = NewSignalPeripherals()
+			probes[j] = make([]Probe, len(upstream))
+			for i := range upstream {
+				lane, activity := make(chan Signal), make(chan Signal)
+				lanes[i] = append(lanes[i], lane)
+				activities[j] = append(activities[j], activity)
+				probes[j][i] = Probe {Read: make(chan float64), Write: make(chan float64)}
+				go STDPSynapse (spiking, weights[l][j][i], Tag {Source: i, Target: len(lanes[i]) - 1}, SignalPeripherals {Input: lane, Output: neurons[j].Input}, activity, probes[j][i], network.cancelchan)
+			}
+		}
+		spawn(upstream, lanes, taps)
+		network.probes = append(network.probes, probes)
+		upstream, taps = neurons, activities
+	}
+	lanes := make([][]chan Signal, len(upstream))
+	for j := range upstream {
+		network.outputs = append(network.outputs, make(chan Signal))
+		lanes[j] = []chan Signal {network.outputs[j]}
+	}
+	spawn(upstream, lanes, taps)
+	if devspiking {fmt.Printf("\n%v: Spiking network initialized...\n", time.Now())}
+	return network
+}
+
+func (network *SpikingNetwork) Run (train [][]bool, learn bool) [][]bool {
+	spikes := make([][]bool, len(train))
+	for t, inputs := range train {
+		for i, source := range network.sources {
+			source.Input <- Signal {Step: t, Infer: !learn, Values: []float64 {spike(inputs[i])}}
+		}
+		spikes[t] = make([]bool, len(network.outputs))
+		for j, output := range network.outputs {
+			spikes[t][j] = (<- output).Values[0] > 0
+		}
+	}
+	return spikes
+}
+
+func (network *SpikingNetwork) Weights () [][][]float64 {
+	weights := make([][][]float64, len(network.probes))
+	for l := range network.probes {
+		weights[l] = make([][]float64, len(network.probes[l]))
+		for j := range network.probes[l] {
+			weights[l][j] = make([]float64, len(network.probes[l][j]))
+			for i, probe := range network.probes[l][j] {
+				weights[l][j][i] = <- probe.Read
+			}
+		}
+	}
+	return weights
+}
+
+func (network *SpikingNetwork) SetWeights (weights [][][]float64) {
+	for l := range network.probes {
+		for j := range network.probes[l] {
+			for i, probe := range network.probes[l][j] {
+				probe.Write <- weights[l][j][i]
+			}
+		}
+	}
+}
+
+func (network *SpikingNetwork) Faults () chan Fault {
+	return network.faults
+}
+
+func (network *SpikingNetwork) Close () {
+	close(network.cancelchan)
+}
+
+func (spiking Spiking) Simulate (weights [][][]float64, train [][]bool, learn bool) ([][]bool, [][][]float64) {
+	spiking = spiking.defaults()
+	weights = copyWeights(weights)
+	potentials := make([][]float64, len(weights))
+	refractories := make([][]int, len(weights))
+	pretraces, posttraces := zeroWeights(weights), zeroWeights(weights)
+	for l := range weights {
+		potentials[l], refractories[l] = make([]float64, len(weights[l])), make([]int, len(weights[l]))
+	}
+	spikes := make([][]bool, len(train))
+	for t, fired := range train {
+		for l := range weights {
+			next := make([]bool, len(weights[l]))
+			for j := range weights[l] {
+				var current float64
+				for i, weight := range weights[l][j] {
+					current = current + spike(fired[i]) * weight
+					pretraces[l][j][i] = pretraces[l][j][i] * spiking.Trace + spike(fired[i])
+					if learn && fired[i] {weights[l][j][i] = spiking.clip(weights[l][j][i] - spiking.Depression * posttraces[l][j][i])}
+				}
+				potentials[l][j], refractories[l][j], next[j] = spiking.integrate(potentials[l][j], refractories[l][j], current)
+				for i := range weights[l][j] {
+					posttraces[l][j][i] = posttraces[l][j][i] * spiking.Trace + spike(next[j])
+					if learn && next[j] {weights[l][j][i] = spiking.clip(weights[l][j][i] + spiking.Potentiation * pretraces[l][j][i])}
+				}
+			}
+			fired = next
+		}
+		spikes[t] = fired
+	}
+	return spikes, weights
+}
+
+func Poisson (rates []float64, steps int, seed int64) [][]bool {
+	random := rand.New(rand.NewSource(seed))
+	train := make([][]bool, steps)
+	for t := range train {
+		train[t] = make([]bool, len(rates))
+		for i, rate := range rates {
+			train[t][i] = random.Float64() < rate
+		}
+	}
+	return train
+}
+
+func Rates (train [][]bool) []float64 {
+	if len(train) == 0 {return nil}
+	rates := make([]float64, len(train[0]))
+	for _, spikes := range train {
+		for i, fired := range spikes {
+			rates[i] = rates[i] + spike(fired)
+		}
+	}
+	for i := range rates {
+		rates[i] = rates[i] / float64(len(train))
+	}
+	return rates
+}
@@ -0,0 +1,184 @@
+package ann
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Spiking_Neuron_Dynamics (t *testing.T) {
+	resultchan := make(chan [][]bool)
+	var timeout time.Duration = 1000
+	spiking := Spiking {Threshold: 1, Leak: 0.9, Refractory: 2}
+	weights := [][][]float64 {{{0.6}}}
+	train := make([][]bool, 10)
+	for step := range train {
+		train[step] = []bool {true}
+	}
+	network := NewSpikingNetwork(spiking, 1, weights)
+	defer network.Close()
+
+	go func() {
+		resultchan <- network.Run(train, false)
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Spiking neuron timed out")
+		t.Fail()
+		return
+	case spikes := <- resultchan:
+		expected, _ := spiking.Simulate(weights, train, false)
+		for step, fired := range []bool {false, true, false, false, false, true, false, false, false, true} {
+			if spikes[step][0] != fired || expected[step][0] != fired {
+				t.Log("Failure - Leaky integrate-and-fire dynamics are wrong")
+				t.Log(step, spikes, expected)
+				t.Fail()
+				return
+			}
+		}
+		if rates := Rates(spikes); rates[0] != 0.3 {
+			t.Log("Failure - Firing rate miscounted")
+			t.Log(rates)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Neuron integrates, fires and stays refractory")
+		return
+	}
+}
+
+func Test_Spiking_Nucleus_Batch (t *testing.T) {
+	resultchan := make(chan [][]float64)
+	var timeout time.Duration = 100
+	spiking := Spiking {Threshold: 1, Leak: 0}
+	internals := NewSignalPeripherals()
+	cancelchan := make(chan struct{})
+	defer close(cancelchan)
+	currents := []float64 {0.6, 1.2, 0.5}
+
+	go LeakyNucleus (spiking, internals, cancelchan)
+	go func() {
+		var outputs [][]float64
+		for step := 0; step < 4; step++ {
+			internals.Input <- Signal {Step: step, Values: currents}
+			outputs = append(outputs, (<- internals.Output).Values)
+		}
+		resultchan <- outputs
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Spiking nucleus batch timed out")
+		t.Fail()
+		return
+	case outputs := <- resultchan:
+		for step := range outputs {
+			if len(outputs[step]) != len(currents) {
+				t.Log("Failure - Spiking nucleus dropped batch values")
+				t.Log(outputs)
+				t.Fail()
+				return
+			}
+			for b, current := range currents {
+				potential, refractory := spiking.Reset, 0
+				var fired bool
+				for s := 0; s <= step; s++ {
+					potential, refractory, fired = spiking.integrate(potential, refractory, current)
+				}
+				if outputs[step][b] != spike(fired) {
+					t.Log("Failure - Spiking nucleus batch differs from single neurons without leak")
+					t.Log(step, outputs)
+					t.Fail()
+					return
+				}
+			}
+		}
+		if spikes, _ := spiking.Simulate([][][]float64 {{{0.6}}}, [][]bool {{true}, {true}, {true}}, false); Rates(spikes)[0] != 0 {
+			t.Log("Failure - Spiking simulation replaced a zero leak")
+			t.Log(spikes)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Spiking nucleus integrates every batch value and keeps a zero leak")
+		return
+	}
+}
+
+func Test_Spiking_STDP_Timing (t *testing.T) {
+	spiking := NewSpiking()
+	spiking.Refractory = 3
+	weights := [][][]float64 {{{1, 0.5}}}
+	before := [][]bool {{false, true}, {true, false}, {false, false}, {false, false}}
+	after := [][]bool {{true, false}, {false, true}, {false, false}, {false, false}}
+	_, potentiated := spiking.Simulate(weights, before, true)
+	_, depressed := spiking.Simulate(weights, after, true)
+	_, frozen := spiking.Simulate(weights, before, false)
+	if potentiated[0][0][1] <= 0.5 || depressed[0][0][1] >= 0.5 || frozen[0][0][1] != 0.5 || potentiated[0][0][0] != 1 {
+		t.Log("Failure - Spike-timing-dependent plasticity has the wrong sign")
+		t.Log(potentiated, depressed, frozen)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Pre-before-post potentiates and post-before-pre depresses")
+}
+
+func Test_Spiking_Network_Simulate (t *testing.T) {
+	resultchan := make(chan float64)
+	var timeout time.Duration = 2000
+	spiking := NewSpiking()
+	spiking.Leak, spiking.Refractory, spiking.Potentiation, spiking.Depression = 0.8, 1, 0.05, 0.04
+	weights := RandomWeights(4, []Layer {{Neurons: 5}, {Neurons: 2}}, 17)
+	for l := range weights {
+		for j := range weights[l] {
+			weights[l][j] = weights[l][j][:len(weights[l][j]) - 1]
+			for i := range weights[l][j] {
+				weights[l][j][i] = math.Abs(weights[l][j][i])
+			}
+		}
+	}
+	train := Poisson([]float64 {0.6, 0.3, 0.5, 0.1}, 200, 4)
+	network := NewSpikingNetwork(spiking, 4, weights)
+	defer network.Close()
+	var spikes [][]bool
+
+	go func() {
+		var divergence float64
+		spikes = network.Run(train, true)
+		expected, learned := spiking.Simulate(weights, train, true)
+		for step := range spikes {
+			for j := range spikes[step] {
+				if spikes[step][j] != expected[step][j] {divergence = math.Inf(1)}
+			}
+		}
+		for l, layer := range network.Weights() {
+			for j := range layer {
+				for i, weight := range layer[j] {
+					divergence = math.Max(divergence, math.Abs(weight - learned[l][j][i]))
+				}
+			}
+		}
+		resultchan <- divergence
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Spiking network timed out")
+		t.Fail()
+		return
+	case divergence := <- resultchan:
+		if divergence > 1e-12 {
+			t.Log("Failure - Spiking network diverged from the simulation")
+			t.Log(divergence)
+			t.Fail()
+			return
+		}
+		if rates := Rates(spikes); rates[0] == 0 && rates[1] == 0 {
+			t.Log("Failure - Spiking network never fired")
+			t.Fail()
+			return
+		}
+		t.Log("Success - Spiking network matches the simulation under STDP")
+		return
+	}
+}
@@ -0,0 +1,390 @@
+package ann
+
+import (
+	"fmt"
+	"time"
+)
+
+const devtopology bool = false
+
+type Topology interface {
+	AddNeuron (l int, incoming []float64, outgoing []float64) error
+	RemoveNeuron (l int, j int) error
+	AddSynapse (l int, j int, i int, weight float64) error
+	RemoveSynapse (l int, j int, i int) error
+}
+
+type junction struct {
+	node SignalPeripherals
+	lanes []chan Signal
+	terminals []chan Signal
+	fanout Fan
+	fanin Fan
+	clocks []chan Tick
+	cancelchan chan struct{}
+}
+
+type splice struct {
+	lane int
+	slot int
+	probe Probe
+	cancelchan chan struct{}
+}
+
+func plain (layers []Layer, l int) bool {
+	layer := layers[l]
+	if layer.Convolution.Kernel > 0 || layer.Tie != nil || layer.Normalization.Kind != "" || layer.Embedding.Dimensions > 0 || layer.Cell.Name != "" {return false}
+	for _, other := range layers {
+		if other.Tie != nil && other.Tie.Layer == l {return false}
+	}
+	return true
+}
+
+func outlets (inputs int, layers []Layer, k int, l int) []int {
+	var offsets []int
+	fanin := inputs
+	if k > 0 {fanin = layers[k - 1].Neurons}
+	if k == l + 1 {offsets = append(offsets, 0)}
+	offset := fanin + 1
+	for _, r := range recurrence(layers, k) {
+		if r == l {offsets = append(offsets, offset)}
+		offset = offset + layers[r].Neurons
+	}
+	return offsets
+}
+
+func copyMask (mask [][]bool) [][]bool {
+	if mask == nil {return nil}
+	copied := make([][]bool, len(mask))
+	for j := range mask {
+		copied[j] = append([]bool {}, mask[j]...)
+	}
+	return copied
+}
+
+func fullMask (weights [][]float64) [][]bool {
+	mask := make([][]bool, len(weights))
+	for j := range mask {
+		mask[j] = make([]bool, len(weights[j]))
+		for i := range mask[j] {
+			mask[j][i] = true
+		}
+	}
+	return mask
+}
+
+func rewirable (layers []Layer, l int) error {
+	if !plain(layers, l) {return fmt.Errorf("ann: layer %d is not a plain dense layer", l)}
+	if layers[l].Rules != nil {return fmt.Errorf("ann: layer %d learns by local rules", l)}
+	if l > 0 && layers[l - 1].Rules != nil {return fmt.Errorf("ann: layer %d taps the activity of layer %d", l - 1, l)}
+	return nil
+}
+
+func editable (inputs int, layers []Layer, l int) error {
+	if l < 0 || l >= len(layers) {return fmt.Errorf("ann: no layer %d", l)}
+	for k := range layers {
+		if k != l && len(outlets(inputs, layers, k, l)) == 0 {continue}
+		if err := rewirable(layers, k); err != nil {return err}
+	}
+	return nil
+}
+
+func resize (inputs int, layers []Layer, weights [][][]float64, l int, j int, grow bool, outgoing []float64) ([]Layer, [][][]float64) {
+	layers, weights = append([]Layer {}, layers...), copyWeights(weights)
+	for k := range layers {
+		offsets := outlets(inputs, layers, k, l)
+		if len(offsets) == 0 {continue}
+		layers[k].Mask = copyMask(layers[k].Mask)
+		for o := len(offsets) - 1; o >= 0; o-- {
+			column := offsets[o] + j
+			for n := range weights[k] {
+				if grow {
+					weight := 0.0
+					if k == l + 1 && o == 0 {weight = outgoing[n]}
+					weights[k][n] = append(weights[k][n][:column], append([]float64 {weight}, weights[k][n][column:]...)...)
+					if layers[k].Mask != nil {layers[k].Mask[n] = append(layers[k].Mask[n][:column], append([]bool {true}, layers[k].Mask[n][column:]...)...)}
+				} else {
+					weights[k][n] = append(weights[k][n][:column], weights[k][n][column + 1:]...)
+					if layers[k].Mask != nil {layers[k].Mask[n] = append(layers[k].Mask[n][:column], layers[k].Mask[n][column + 1:]...)}
+				}
+			}
+		}
+	}
+	return layers, weights
+}
+
+func AddNeuron (inputs int, layers []Layer, weights [][][]float64, l int, incoming []float64, outgoing []float64) ([]Layer, [][][]float64, error) {
+	if err := editable(inputs, layers, l); err != nil {return nil, nil, err}
+	if l + 1 < len(layers) && len(outgoing) != layers[l + 1].Neurons {return nil, nil, fmt.Errorf("ann: layer %d expects %d outgoing weights", l, layers[l + 1].Neurons)}
+	layers, weights = resize(inputs, layers, weights, l, layers[l].Neurons, true, outgoing)
+	layers[l].Neurons++
+	width := len(spans(inputs, layers, l)[0])
+	if len(incoming) != width {return nil, nil, fmt.Errorf("ann: layer %d expects %d incoming weights", l, width)}
+	weights[l] = append(weights[l], append([]float64 {}, incoming...))
+	if layers[l].Mask != nil {
+		row := make([]bool, width)
+		for i := range row {
+			row[i] = true
+		}
+		layers[l].Mask = append(layers[l].Mask, row)
+	}
+	return layers, weights, nil
+}
+
+func RemoveNeuron (inputs int, layers []Layer, weights [][][]float64, l int, j int) ([]Layer, [][][]float64, error) {
+	if err := editable(inputs, layers, l); err != nil {return nil, nil, err}
+	if j < 0 || j >= layers[l].Neurons || layers[l].Neurons == 1 {return nil, nil, fmt.Errorf("ann: cannot remove neuron %d from layer %d", j, l)}
+	layers, weights = resize(inputs, layers, weights, l, j, false, nil)
+	weights[l] = append(weights[l][:j], weights[l][j + 1:]...)
+	if layers[l].Mask != nil {layers[l].Mask = append(layers[l].Mask[:j], layers[l].Mask[j + 1:]...)}
+	layers[l].Neurons--
+	for k := range layers {
+		for n := range layers[k].Mask {
+			connected := false
+			for _, present := range layers[k].Mask[n] {
+				connected = connected || present
+			}
+			if !connected {return nil, nil, fmt.Errorf("ann: removing neuron %d from layer %d disconnects neuron %d in layer %d", j, l, n, k)}
+		}
+	}
+	return layers, weights, nil
+}
+
+func rewire (layers []Layer, weights [][][]float64, l int, j int, i int, connected bool, weight float64) ([]Layer, [][][]float64, error) {
+	if l < 0 || l >= len(layers) {return nil, nil, fmt.Errorf("ann: no layer %d", l)}
+	if err := rewirable(layers, l); err != nil {return nil, nil, err}
+	if j < 0 || j >= len(weights[l]) || i < 0 || i >= len(weights[l][j]) {return nil, nil, fmt.Errorf("ann: no synapse %d to neuron %d in layer %d", i, j, l)}
+	layers, weights = append([]Layer {}, layers...), copyWeights(weights)
+	mask := copyMask(layers[l].Mask)
+	if mask == nil {mask = fullMask(weights[l])}
+	if mask[j][i] == connected {return nil, nil, fmt.Errorf("ann: synapse %d to neuron %d in layer %d is unchanged", i, j, l)}
+	mask[j][i], weights[l][j][i] = connected, weight
+	remaining, complete := 0, true
+	for n := range mask {
+		for _, present := range mask[n] {
+			if present && n == j {remaining++}
+			complete = complete && present
+		}
+	}
+	if remaining == 0 {return nil, nil, fmt.Errorf("ann: cannot remove the last synapse to neuron %d in layer %d", j, l)}
+	if complete {mask = nil}
+	layers[l].Mask = mask
+	return layers, weights, nil
+}
+
+func AddSynapse (layers []Layer, weights [][][]float64, l int, j int, i int, weight float64) ([]Layer, [][][]float64, error) {
+	return rewire(layers, weights, l, j, i, true, weight)
+}
+
+func RemoveSynapse (layers []Layer, weights [][][]float64, l int, j int, i int) ([]Layer, [][][]float64, error) {
+	return rewire(layers, weights, l, j, i, false, 0)
+}
+
+func vacancy (lanes []chan Signal) ([]chan Signal, int) {
+	for i, lane := range lanes {
+		if lane == nil {return lanes, i}
+	}
+	return append(lanes, nil), len(lanes)
+}
+
+func probed (splices [][]*splice) [][]Probe {
+	probes := make([][]Probe, len(splices))
+	for j := range splices {
+		probes[j] = make([]Probe, len(splices[j]))
+		for i, splice := range splices[j] {
+			if splice != nil {probes[j][i] = splice.probe}
+		}
+	}
+	return probes
+}
+
+func (network *Network) branch () chan struct{} {
+	cancelchan := make(chan struct{})
+	network.branches[cancelchan] = true
+	return cancelchan
+}
+
+func (network *Network) cut (cancelchan chan struct{}) {
+	if !network.branches[cancelchan] {return}
+	delete(network.branches, cancelchan)
+	close(cancelchan)
+}
+
+func (network *Network) detach (junction *junction) {
+	network.cut(junction.cancelchan)
+	owned := map[chan Tick]bool {}
+	for _, clock := range junction.clocks {
+		owned[clock] = true
+	}
+	var clocks []chan Tick
+	for _, clock := range network.clocks {
+		if !owned[clock] {clocks = append(clocks, clock)}
+	}
+	network.clocks = clocks
+}
+
+func (network *Network) splice (weight float64, source SignalPeripherals, lanes []chan Signal, target SignalPeripherals, terminals []chan Signal) ([]chan Signal, []chan Signal, *splice) {
+	lanes, lane := vacancy(lanes)
+	terminals, slot := vacancy(terminals)
+	lanes[lane], terminals[slot] = make(chan Signal), make(chan Signal)
+	spliced := &splice {lane: lane, slot: slot, probe: Probe {Read: make(chan float64), Write: make(chan float64)}, cancelchan: network.branch()}
+	synapse := SignalPeripherals {Input: lanes[lane], Output: target.Input, Upfeed: terminals[slot], Downfeed: source.Upfeed}
+	go TaggedSynapse (weight, Tag {Source: slot, Target: lane}, synapse, spliced.probe, spliced.cancelchan)
+	return lanes, terminals, spliced
+}
+
+func (network *Network) upstream (l int) []*junction {
+	junctions := append(append([]*junction {}, network.nodes[l]...), network.biases[l])
+	for _, r := range recurrence(network.Layers, l) {
+		junctions = append(junctions, network.delays[r]...)
+	}
+	return junctions
+}
+
+func (network *Network) connect (l int, j int, i int, weight float64) {
+	source, target := network.upstream(l)[i], network.nodes[l + 1][j]
+	source.lanes, target.terminals, network.splices[l][j][i] = network.splice(weight, source.node, source.lanes, target.node, target.terminals)
+}
+
+func (network *Network) disconnect (l int, j int, i int) {
+	spliced := network.splices[l][j][i]
+	if spliced == nil {return}
+	source, target := network.upstream(l)[i], network.nodes[l + 1][j]
+	network.cut(spliced.cancelchan)
+	source.lanes[spliced.lane], target.terminals[spliced.slot], network.splices[l][j][i] = nil, nil, nil
+}
+
+func (network *Network) renegotiate (junctions []*junction) {
+	for _, junction := range junctions {
+		if len(slots(live(junction.lanes))) == 0 {network.ground(junction)}
+		junction.fanout.Lanes <- append([]chan Signal {}, junction.lanes...)
+		junction.fanout.Live <- live(junction.lanes)
+		if junction.terminals == nil {continue}
+		junction.fanin.Lanes <- append([]chan Signal {}, junction.terminals...)
+		junction.fanin.Live <- live(junction.terminals)
+	}
+}
+
+func (network *Network) around (l int) []*junction {
+	junctions := network.upstream(l)
+	for k := range network.Layers {
+		if k != l && len(outlets(network.Inputs, network.Layers, k, l)) == 0 {continue}
+		junctions = append(junctions, network.nodes[k + 1]...)
+	}
+	return junctions
+}
+
+func (network *Network) settle (layers []Layer, l int, junctions []*junction) {
+	network.Layers = layers
+	for k := range layers {
+		if k != l && len(outlets(network.Inputs, layers, k, l)) == 0 {continue}
+		network.probes[k] = probed(network.splices[k])
+	}
+	network.renegotiate(junctions)
+	if devtopology {fmt.Printf("\n%v: Network rewired layer %d in place...\n", time.Now(), l)}
+}
+
+func (network *Network) AddNeuron (l int, incoming []float64, outgoing []float64) error {
+	network.release()
+	layers, weights, err := AddNeuron(network.Inputs, network.Layers, network.Weights(), l, incoming, outgoing)
+	if err != nil {return err}
+	j := network.Layers[l].Neurons
+	neuron := &junction {node: NewSignalPeripherals(), fanout: NewFan(), cancelchan: network.branch()}
+	if l + 1 == len(layers) {neuron.lanes = []chan Signal {neuron.node.Output}}
+	network.nodes[l + 1] = append(network.nodes[l + 1], neuron)
+	if network.delays[l] != nil {network.delays[l] = append(network.delays[l], &junction {node: NewSignalPeripherals(), fanout: NewFan(), cancelchan: network.branch()})}
+	network.Layers = layers
+	for k := range layers {
+		for _, offset := range outlets(network.Inputs, layers, k, l) {
+			column := offset + j
+			for n := range network.splices[k] {
+				network.splices[k][n] = append(network.splices[k][n][:column], append([]*splice {nil}, network.splices[k][n][column:]...)...)
+				network.connect(k, n, column, weights[k][n][column])
+			}
+		}
+	}
+	network.splices[l] = append(network.splices[l], make([]*splice, len(weights[l][j])))
+	for i, weight := range weights[l][j] {
+		network.connect(l, j, i, weight)
+	}
+	if len(neuron.lanes) == 0 {network.ground(neuron)}
+	network.echo(network.delays[l], j, neuron)
+	network.spawn(network.LearnRate, layers[l], neuron, [][]chan Signal {neuron.terminals}, nil)
+	if network.delays[l] != nil {
+		delay := network.delays[l][j]
+		Source(delay.node, delay.lanes, delay.fanout, network.faults, delay.cancelchan)
+	}
+	if l + 1 == len(layers) {network.outputs = append(network.outputs, neuron.node)}
+	network.settle(layers, l, network.around(l))
+	return nil
+}
+
+func (network *Network) RemoveNeuron (l int, j int) error {
+	network.release()
+	layers, _, err := RemoveNeuron(network.Inputs, network.Layers, network.Weights(), l, j)
+	if err != nil {return err}
+	for i := range network.splices[l][j] {
+		network.disconnect(l, j, i)
+	}
+	for k := range network.Layers {
+		offsets := outlets(network.Inputs, network.Layers, k, l)
+		for o := len(offsets) - 1; o >= 0; o-- {
+			column := offsets[o] + j
+			for n := range network.splices[k] {
+				network.disconnect(k, n, column)
+				network.splices[k][n] = append(network.splices[k][n][:column], network.splices[k][n][column + 1:]...)
+			}
+		}
+	}
+	network.splices[l] = append(network.splices[l][:j], network.splices[l][j + 1:]...)
+	network.detach(network.nodes[l + 1][j])
+	network.nodes[l + 1] = append(network.nodes[l + 1][:j], network.nodes[l + 1][j + 1:]...)
+	if network.delays[l] != nil {
+		network.detach(network.delays[l][j])
+		network.delays[l] = append(network.delays[l][:j], network.delays[l][j + 1:]...)
+	}
+	if l + 1 == len(layers) {network.outputs = append(network.outputs[:j], network.outputs[j + 1:]...)}
+	network.Layers = layers
+	network.settle(layers, l, network.around(l))
+	return nil
+}
+
+func (network *Network) AddSynapse (l int, j int, i int, weight float64) error {
+	network.release()
+	layers, _, err := AddSynapse(network.Layers, network.Weights(), l, j, i, weight)
+	if err != nil {return err}
+	network.connect(l, j, i, weight)
+	network.settle(layers, l, []*junction {network.upstream(l)[i], network.nodes[l + 1][j]})
+	return nil
+}
+
+func (network *Network) RemoveSynapse (l int, j int, i int) error {
+	network.release()
+	layers, _, err := RemoveSynapse(network.Layers, network.Weights(), l, j, i)
+	if err != nil {return err}
+	network.disconnect(l, j, i)
+	network.settle(layers, l, []*junction {network.upstream(l)[i], network.nodes[l + 1][j]})
+	return nil
+}
+
+func (matrix *Matrix) reshape (layers []Layer, weights [][][]float64, err error) error {
+	if err != nil {return err}
+	matrix.Layers, matrix.Weights = layers, weights
+	return nil
+}
+
+func (matrix *Matrix) AddNeuron (l int, incoming []float64, outgoing []float64) error {
+	return matrix.reshape(AddNeuron(matrix.Inputs, matrix.Layers, matrix.Weights, l, incoming, outgoing))
+}
+
+func (matrix *Matrix) RemoveNeuron (l int, j int) error {
+	return matrix.reshape(RemoveNeuron(matrix.Inputs, matrix.Layers, matrix.Weights, l, j))
+}
+
+func (matrix *Matrix) AddSynapse (l int, j int, i int, weight float64) error {
+	return matrix.reshape(AddSynapse(matrix.Layers, matrix.Weights, l, j, i, weight))
+}
+
+func (matrix *Matrix) RemoveSynapse (l int, j int, i int) error {
+	return matrix.reshape(RemoveSynapse(matrix.Layers, matrix.Weights, l, j, i))
+}
@@ -0,0 +1,200 @@
+package ann
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Matrix_Topology_Recurrent_Growth (t *testing.T) {
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {0, 1}}, {Neurons: 2, Activation: Activations["sigmoid"], Recurrent: []int {0}}}
+	matrix := NewMatrix(0.1, 1, layers, RandomWeights(1, layers, 3))
+	inputs := [][]float64 {{0.3}, {0.9}, {0.1}}
+	before := matrix.PredictSequence(inputs)
+	if err := matrix.AddNeuron(0, make([]float64, 1 + 1 + 4 + 2), []float64 {0, 0}); err != nil {
+		t.Log("Failure - Growing a recurrent layer was refused")
+		t.Log(err)
+		t.Fail()
+		return
+	}
+	if len(matrix.Weights[0][0]) != 8 || len(matrix.Weights[1][0]) != 4 + 1 + 4 || matrix.Weights[0][0][5] != 0 || matrix.Weights[1][0][8] != 0 {
+		t.Log("Failure - Growing a recurrent layer misplaced the new columns")
+		t.Log(matrix.Weights)
+		t.Fail()
+		return
+	}
+	for step, output := range matrix.PredictSequence(inputs) {
+		for j := range output {
+			if math.Abs(output[j] - before[step][j]) > 1e-12 {
+				t.Log("Failure - A disconnected neuron changed the sequence predictions")
+				t.Log(step, output, before[step])
+				t.Fail()
+				return
+			}
+		}
+	}
+	if err := matrix.RemoveNeuron(0, 3); err != nil || len(matrix.Weights[0][0]) != 7 || len(matrix.Weights[1][0]) != 3 + 1 + 3 {
+		t.Log("Failure - Shrinking a recurrent layer left stale columns")
+		t.Log(err, matrix.Weights)
+		t.Fail()
+		return
+	}
+	t.Log("Success - Recurrent layers grow and shrink without disturbing predictions")
+}
+
+func Test_Topology_Refusals (t *testing.T) {
+	layers := []Layer {{Neurons: 1, Activation: Activations["sigmoid"]}, BatchNormalization(1), {Neurons: 1, Activation: Activations["sigmoid"]}}
+	matrix := NewMatrix(0.1, 1, layers, RandomWeights(1, layers, 1))
+	if matrix.AddNeuron(0, []float64 {0, 0}, []float64 {}) == nil || matrix.AddNeuron(2, []float64 {0}, nil) == nil || matrix.RemoveNeuron(2, 0) == nil {
+		t.Log("Failure - Topology edits accepted a normalized neighbour or malformed weights")
+		t.Fail()
+		return
+	}
+	if matrix.RemoveSynapse(2, 0, 0) != nil || matrix.RemoveSynapse(2, 0, 1) == nil || matrix.RemoveSynapse(2, 0, 0) == nil {
+		t.Log("Failure - Synapse removal left a neuron without inputs or removed a synapse twice")
+		t.Fail()
+		return
+	}
+	if matrix.AddSynapse(2, 0, 0, 0.5) != nil || matrix.Layers[2].Mask != nil || matrix.Weights[2][0][0] != 0.5 {
+		t.Log("Failure - Reconnecting every synapse kept the mask")
+		t.Fail()
+		return
+	}
+	t.Log("Success - Topology edits refuse unsupported layers and keep neurons connected")
+}
+
+func Test_Network_Topology_Matrix (t *testing.T) {
+	resultchan := make(chan []Divergence)
+	var timeout time.Duration = 1000
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"]}, {Neurons: 2, Activation: Activations["sigmoid"]}}
+	weights := RandomWeights(2, layers, 31)
+	network, _ := NewNetwork(0.5, 2, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.5, 2, layers, weights)
+	regimen := Regimen {TrainingSets: []TrainingSet {
+		{Input: []float64 {0, 1}, Expect: []float64 {1, 0}},
+		{Input: []float64 {1, 0}, Expect: []float64 {1, 0}},
+		{Input: []float64 {1, 1}, Expect: []float64 {0, 1}},
+	}}
+	edits := []func (engine Topology) error {
+		func (engine Topology) error {return engine.AddNeuron(0, []float64 {0.4, -0.3, 0.1}, []float64 {0.2, -0.6})},
+		func (engine Topology) error {return engine.RemoveSynapse(1, 0, 2)},
+		func (engine Topology) error {return engine.RemoveNeuron(0, 1)},
+	}
+
+	go func() {
+		var divergences []Divergence
+		for _, edit := range append(edits, nil) {
+			for epoch := 0; epoch < 3; epoch++ {
+				expected := matrix.LearnBatch(regimen.TrainingSets)
+				for s, output := range network.LearnBatch(regimen.TrainingSets) {
+					for j := range output {
+						divergences = append(divergences, Divergence {Sample: s, Output: math.Abs(output[j] - expected[s][j])})
+					}
+				}
+			}
+			divergences = append(divergences, CrossCheck(network, matrix, regimen)...)
+			if edit == nil {break}
+			if edit(network) != nil || edit(matrix) != nil {divergences = append(divergences, Divergence {Output: math.Inf(1)})}
+		}
+		resultchan <- divergences
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Rewired network timed out")
+		t.Fail()
+		return
+	case divergences := <- resultchan:
+		for _, divergence := range divergences {
+			if divergence.Output > 1e-9 || divergence.Weight > 1e-9 {
+				t.Log("Failure - Rewired network diverged from the matrix engine")
+				t.Log(divergence)
+				t.Fail()
+				return
+			}
+		}
+		var buffer bytes.Buffer
+		network.Save(&buffer)
+		model, err := LoadModel(&buffer)
+		if err != nil || model.Layers[0].Neurons != 3 || model.Layers[1].Mask == nil || model.Layers[1].Mask[0][1] || len(model.Weights[1][0]) != 4 {
+			t.Log("Failure - Rewired topology lost in the saved model")
+			t.Log(err, model.Layers)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Rewired network matches the matrix engine")
+		return
+	}
+}
+
+func Test_Network_Topology_InPlace (t *testing.T) {
+	resultchan := make(chan []float64)
+	var timeout time.Duration = 1000
+	layers := []Layer {{Neurons: 3, Activation: Activations["sigmoid"], Recurrent: []int {0, 1}}, {Neurons: 2, Activation: Activations["sigmoid"], Aggregation: Aggregations["product"]}}
+	weights := RandomWeights(1, layers, 43)
+	network, _ := NewNetwork(0.3, 1, layers, weights)
+	defer network.Close()
+	matrix := NewMatrix(0.3, 1, layers, weights)
+	sequences := []Sequence {bits(5, 19), bits(5, 26)}
+	edits := []func (engine Topology) error {
+		func (engine Topology) error {return engine.AddNeuron(0, []float64 {0.2, -0.1, 0.3, 0.1, -0.2, 0.4, 0.1, -0.3}, []float64 {0.5, -0.4})},
+		func (engine Topology) error {return engine.RemoveSynapse(1, 0, 1)},
+		func (engine Topology) error {return engine.AddSynapse(1, 0, 1, 0.3)},
+		func (engine Topology) error {return engine.RemoveNeuron(0, 0)},
+	}
+
+	go func() {
+		var divergence float64
+		kept, removed := network.probes[1][1][3], network.probes[1][0][1]
+		for e, edit := range append(edits, nil) {
+			for epoch := 0; epoch < 3; epoch++ {
+				expected := matrix.LearnSequences(sequences, 3)
+				for s, outputs := range network.LearnSequences(sequences, 3) {
+					for step := range outputs {
+						for j := range outputs[step] {
+							divergence = math.Max(divergence, math.Abs(outputs[step][j] - expected[s][step][j]))
+						}
+					}
+				}
+			}
+			if edit == nil {break}
+			if edit(network) != nil || edit(matrix) != nil {divergence = math.Inf(1)}
+			if e == 1 {
+				time.Sleep(5 * time.Millisecond)
+				select {
+				case <- removed.Read:
+					divergence = math.Inf(1)
+				case <- time.After(10 * time.Millisecond):
+				}
+			}
+		}
+		for l, after := range network.Weights() {
+			for j := range after {
+				for i := range after[j] {
+					divergence = math.Max(divergence, math.Abs(after[j][i] - matrix.Weights[l][j][i]))
+				}
+			}
+		}
+		var persisted float64
+		if network.probes[1][1][3] == kept {persisted = 1}
+		resultchan <- []float64 {divergence, persisted}
+	}()
+
+	select {
+	case <- time.After(timeout * time.Millisecond):
+		t.Log("Failure - Network rewired in place timed out")
+		t.Fail()
+		return
+	case result := <- resultchan:
+		if result[0] > 1e-9 || result[1] != 1 {
+			t.Log("Failure - Rewiring in place diverged from the matrix engine or replaced untouched synapses")
+			t.Log(result)
+			t.Fail()
+			return
+		}
+		t.Log("Success - Recurrent layers rewire in place and match the matrix engine")
+		return
+	}
+}